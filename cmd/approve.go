@@ -2,61 +2,91 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
 	"github.com/jackchuka/gh-dep/internal/github"
-	"github.com/jackchuka/gh-dep/internal/ui"
+	"github.com/jackchuka/gh-dep/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var approveCmd = &cobra.Command{
 	Use:   "approve",
-	Short: "Bulk approve all PRs in a group",
+	Short: "Bulk approve the PRs in one or more groups",
 	RunE:  runApprove,
 }
 
 var (
-	approveGroup  string
-	approveDryRun bool
+	approveSelect      groupSelection
+	approveScope       string
+	approveMaxAge      time.Duration
+	approveDryRun      bool
+	approveProfile     string
+	approveConcurrency int
 )
 
 func init() {
-	approveCmd.Flags().StringVar(&approveGroup, "group", "", "Group key (package@version)")
-	_ = approveCmd.MarkFlagRequired("group")
+	approveSelect.addFlags(approveCmd)
+	approveCmd.Flags().StringVar(&approveScope, "scope", "", "Cached scope to use (default: the profile's scope, else the most recent list --group; see groups --scopes)")
+	approveCmd.Flags().StringVar(&approveProfile, "profile", "", "Configured profile whose scope selects the cached groups (unless --scope is set)")
+	approveCmd.Flags().DurationVar(&approveMaxAge, "max-age", 0, "Refuse to act on cached groups older than this (e.g., 30m, 2h)")
 
 	approveCmd.Flags().BoolVar(&approveDryRun, "dry-run", false, "Print actions without executing")
+	approveCmd.Flags().IntVar(&approveConcurrency, "concurrency", executor.DefaultConcurrency, "Repos to work on at once; PRs of a repo run one at a time")
 }
 
 func runApprove(cmd *cobra.Command, args []string) error {
-	c, err := cache.Load()
+	cfg, err := loadConfig(approveProfile)
 	if err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+		return err
 	}
 
-	if c == nil || len(c.Groups) == 0 {
-		return fmt.Errorf("no cached groups found. Run 'gh dep list --group' first")
+	entry, err := loadCachedEntry(resolveCachedScope(approveScope, cfg), approveMaxAge)
+	if err != nil {
+		return err
+	}
+
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		return err
 	}
 
-	prs, ok := c.Groups[approveGroup]
-	if !ok {
-		return fmt.Errorf("group '%s' not found in cache", approveGroup)
+	prs, err := approveSelect.selectPRs(entry, checks.opts)
+	if err != nil {
+		return err
+	}
+	if len(prs) == 0 {
+		fmt.Println("No cached PRs match the selection")
+		return nil
 	}
 
-	display := ui.New(prs, false)
+	runActions(prs, executor.Options{Concurrency: approveConcurrency}, func(pr types.PR) executor.Result {
+		// PRs approved by an earlier run are left alone
+		if pr.State.IsApproved() {
+			return executor.Skip(pr, "approve", fmt.Errorf("already %s", pr.State.Status))
+		}
+
+		if err := cfg.CheckAllowed(pr.Repo, config.ActionApprove); err != nil {
+			return executor.Skip(pr, "approve", err)
+		}
+
+		if err := checks.evaluate(pr).CheckApprove(); err != nil {
+			return executor.Skip(pr, "approve", err)
+		}
 
-	for _, pr := range prs {
 		if approveDryRun {
-			display.PrintAction("approve", pr)
-			continue
+			return executor.Done(pr, "approve", "")
 		}
 
 		if err := github.ApprovePR(pr.Repo, pr.Number); err != nil {
-			display.PrintError("approve", pr, err)
-			continue
+			recordState(pr, types.StatusFailed, fmt.Sprintf("approve: %v", err))
+			return executor.Fail(pr, "approve", err)
 		}
 
-		display.PrintAction("approve", pr)
-	}
+		recordState(pr, types.StatusApproved, "")
+		return executor.Done(pr, "approve", "")
+	})
 
 	return nil
 }
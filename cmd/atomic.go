@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/train"
+	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/jackchuka/gh-dep/internal/ui"
+)
+
+// atomicSelection runs check on every PR of the groups and returns the PRs
+// of the groups in which all of them passed. check reports a PR that can
+// be merged as done. The PRs blocking each other group are printed.
+func atomicSelection(groups []prGroup, concurrency int, check executor.Func) []types.PR {
+	var all []types.PR
+	for _, g := range groups {
+		all = append(all, g.PRs...)
+	}
+
+	fmt.Printf("Checking %d PR(s) in %d group(s) before merging\n", len(all), len(groups))
+	summary := executor.Run(all, check, executor.Options{Concurrency: concurrency})
+
+	blockers := blockedBy(summary.Results)
+	display := ui.New(all, false)
+
+	var eligible []types.PR
+	for _, g := range groups {
+		var blocked []executor.Result
+		for _, pr := range g.PRs {
+			if r, ok := blockers[fmt.Sprintf("%s#%d", pr.Repo, pr.Number)]; ok {
+				blocked = append(blocked, r)
+			}
+		}
+		if len(blocked) == 0 {
+			eligible = append(eligible, g.PRs...)
+			continue
+		}
+
+		fmt.Printf("\nGroup %s is blocked, merging none of its %d PR(s):\n", g.Key, len(g.PRs))
+		for _, r := range blocked {
+			display.PrintAction("blocked", r.PR, r.Err.Error())
+		}
+	}
+	fmt.Println()
+	return eligible
+}
+
+// blockedBy returns the results of the PRs that didn't pass, by repo#number
+func blockedBy(results []executor.Result) map[string]executor.Result {
+	blocked := make(map[string]executor.Result)
+	for _, r := range results {
+		if r.Status != executor.StatusDone {
+			blocked[fmt.Sprintf("%s#%d", r.PR.Repo, r.PR.Number)] = r
+		}
+	}
+	return blocked
+}
+
+// atomicCheck returns the check --atomic runs on every PR: done if it could
+// be merged now, else skipped with why not. With inTrain, PRs the train
+// would rebase pass.
+func atomicCheck(settingsFor mergeSettings, inTrain bool) executor.Func {
+	return func(pr types.PR) executor.Result {
+		if pr.State != nil && pr.State.Status == types.StatusMerged {
+			return executor.Done(pr, "check", "already merged")
+		}
+		_, requireChecks, _, err := settingsFor(pr)
+		if err != nil {
+			return executor.Skip(pr, "check", err)
+		}
+
+		details, err := github.GetPR(pr.Repo, pr.Number)
+		if err != nil {
+			return executor.Skip(pr, "check", fmt.Errorf("failed to fetch PR: %w", err))
+		}
+		if inTrain && details.State == "open" && !details.Draft && train.NeedsRebase(details.MergeableState) {
+			// The train rebases it when its turn comes, and checks CI then
+			return executor.Done(pr, "check", "")
+		}
+
+		var status *github.CheckStatus
+		if requireChecks && !details.Merged {
+			status, err = github.GetCIStatus(pr.Repo, details.HeadSHA)
+			if err != nil {
+				return executor.Skip(pr, "check", fmt.Errorf("failed to check CI status: %w", err))
+			}
+		}
+		if err := github.MergeBlocker(details, status, true); err != nil {
+			return executor.Skip(pr, "check", err)
+		}
+		return executor.Done(pr, "check", "")
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestAtomicSelection(t *testing.T) {
+	groups := []prGroup{
+		{Key: "lodash@4.17.21", PRs: []types.PR{{Repo: "org/web", Number: 1}, {Repo: "org/api", Number: 2}}},
+		{Key: "react@19.0.0", PRs: []types.PR{{Repo: "org/web", Number: 3}, {Repo: "org/app", Number: 4}}},
+		{Key: "eslint@9.0.0", PRs: []types.PR{{Repo: "org/app", Number: 5}}},
+	}
+
+	prs := atomicSelection(groups, 2, func(pr types.PR) executor.Result {
+		switch pr.Number {
+		case 4:
+			return executor.Skip(pr, "check", errors.New("CI checks not passing (state: failure)"))
+		case 5:
+			return executor.Fail(pr, "check", errors.New("boom"))
+		}
+		return executor.Done(pr, "check", "")
+	})
+
+	if len(prs) != 2 || prs[0].Number != 1 || prs[1].Number != 2 {
+		t.Errorf("atomicSelection() = %+v, want only the lodash PRs #1 and #2", prs)
+	}
+}
+
+func TestAtomicCheck(t *testing.T) {
+	cfg := &config.Config{Overrides: map[string]config.Override{
+		"org/legacy": {Modes: []string{config.ActionApprove}},
+	}}
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		t.Fatalf("newPolicyCheck() error = %v", err)
+	}
+	check := atomicCheck(mergeSettingsFor(newTestCommand(), cfg, checks), false)
+
+	// A PR without a recorded state, checked without contacting GitHub
+	if r := check(types.PR{Repo: "org/legacy", Number: 1}); r.Status != executor.StatusSkipped || r.Err == nil {
+		t.Errorf("check(no state) = %+v, want skipped by the repo's modes", r)
+	}
+
+	merged := types.PR{Repo: "org/legacy", Number: 2, State: &types.PRState{Status: types.StatusMerged}}
+	if r := check(merged); r.Status != executor.StatusDone {
+		t.Errorf("check(merged) = %+v, want done", r)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/jackchuka/gh-dep/internal/ui"
+)
+
+// staleAfter is the age after which cached groups trigger a warning
+const staleAfter = time.Hour
+
+// loadCachedEntry loads the cached groups for a scope (the most recent one
+// when empty). With maxAge set, entries older than it are refused;
+// otherwise stale entries only produce a warning on stderr.
+func loadCachedEntry(scope string, maxAge time.Duration) (*types.CacheEntry, error) {
+	entry, err := cache.LoadEntry(scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache: %w", err)
+	}
+
+	if entry == nil || len(entry.Groups) == 0 {
+		if scope != "" {
+			return nil, fmt.Errorf("no cached groups found for scope '%s'. Run 'gh dep groups --scopes' to see cached scopes", scope)
+		}
+		return nil, fmt.Errorf("no cached groups found. Run 'gh dep list --group' first")
+	}
+
+	if err := checkCacheAge(entry, maxAge); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// findCachedGroup returns the PRs of a group from a cache entry
+func findCachedGroup(entry *types.CacheEntry, group string) ([]types.PR, error) {
+	prs, ok := entry.Groups[group]
+	if !ok {
+		return nil, fmt.Errorf("group '%s' not found in cache (scope: %s)", group, entry.Scope)
+	}
+	return prs, nil
+}
+
+func checkCacheAge(entry *types.CacheEntry, maxAge time.Duration) error {
+	if entry.FetchedAt.IsZero() {
+		return nil
+	}
+
+	age := time.Since(entry.FetchedAt)
+	if maxAge > 0 && age > maxAge {
+		return fmt.Errorf("cached groups for scope '%s' are %s old (max %s). Run 'gh dep list --group' again to refresh them", entry.Scope, ui.FormatAge(age), maxAge)
+	}
+	if maxAge == 0 && age > staleAfter {
+		fmt.Fprintf(os.Stderr, "warning: cached groups for scope '%s' are %s old; run 'gh dep list --group' to refresh them\n", entry.Scope, ui.FormatAge(age))
+	}
+	return nil
+}
+
+// recordState writes the outcome of an action on a PR back into the cache.
+// Failing to update the cache doesn't fail the action, so it only warns.
+func recordState(pr types.PR, status string, reason string) {
+	err := cache.RecordState(pr.Repo, pr.Number, types.PRState{
+		Status:    status,
+		Reason:    reason,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to update cache for %s#%d: %v\n", pr.Repo, pr.Number, err)
+	}
+}
+
+// snapshotPRs flattens a cache entry's groups into a list of the PRs that
+// are still pending, each listed once
+func snapshotPRs(entry *types.CacheEntry) []types.PR {
+	seen := make(map[string]bool)
+	var prs []types.PR
+	for _, group := range entry.Groups {
+		for _, pr := range group {
+			key := fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
+			if seen[key] || pr.State.Done() {
+				continue
+			}
+			seen[key] = true
+			prs = append(prs, pr)
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		if prs[i].Repo != prs[j].Repo {
+			return prs[i].Repo < prs[j].Repo
+		}
+		return prs[i].Number < prs[j].Number
+	})
+	return prs
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestSnapshotPRs(t *testing.T) {
+	entry := &types.CacheEntry{
+		Groups: map[string][]types.PR{
+			"lodash@4.17.21": {
+				{Repo: "org/web", Number: 3},
+				{Repo: "org/app", Number: 1, State: &types.PRState{Status: types.StatusMerged}},
+			},
+			"lodash": {
+				{Repo: "org/web", Number: 3},
+				{Repo: "org/api", Number: 2, State: &types.PRState{Status: types.StatusFailed}},
+			},
+		},
+	}
+
+	prs := snapshotPRs(entry)
+
+	var got []string
+	for _, pr := range prs {
+		got = append(got, pr.Repo)
+	}
+	if len(got) != 2 || got[0] != "org/api" || got[1] != "org/web" {
+		t.Fatalf("snapshotPRs() repos = %v, want [org/api org/web]", got)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// canaryMerge is a PR merged into a canary repo and the commit its merge
+// made on the default branch
+type canaryMerge struct {
+	PR  types.PR
+	SHA string
+}
+
+// soakSettings bound how long canary merges are watched
+type soakSettings struct {
+	Soak     time.Duration // how long the default branch CI must stay green
+	Timeout  time.Duration // how much longer it may still be pending after Soak
+	Interval time.Duration // delay between CI checks
+	DryRun   bool          // the canary merges were only previewed; nothing to watch
+}
+
+// splitCanary separates the PRs in the canary repos from the rest,
+// keeping their order
+func splitCanary(prs []types.PR, canaryRepos []string) (canary, rest []types.PR) {
+	for _, pr := range prs {
+		if github.MatchRepo(pr.Repo, canaryRepos) {
+			canary = append(canary, pr)
+		} else {
+			rest = append(rest, pr)
+		}
+	}
+	return canary, rest
+}
+
+// runCanary merges the PRs in the canary repos first, waits for the
+// default branch CI after their merges to pass and stay green for the soak,
+// and only then merges the rest. Anything short of that aborts the rollout.
+func runCanary(prs []types.PR, canaryRepos []string, settings soakSettings, opts executor.Options, fn executor.Func) error {
+	canary, rest := splitCanary(prs, canaryRepos)
+	if len(canary) == 0 {
+		return fmt.Errorf("no selected PRs are in the canary repos (%s)", strings.Join(canaryRepos, ", "))
+	}
+
+	fmt.Printf("Canary: merging %d PR(s) into %s\n\n", len(canary), strings.Join(reposOf(canary), ", "))
+	summary := runActions(canary, opts, fn)
+
+	var merged []canaryMerge
+	for _, r := range summary.Results {
+		if r.Status != executor.StatusDone {
+			return abortRollout(rest, fmt.Errorf("%s#%d was not merged", r.PR.Repo, r.PR.Number))
+		}
+		if settings.DryRun {
+			continue
+		}
+
+		details, err := github.GetPR(r.PR.Repo, r.PR.Number)
+		if err != nil {
+			return abortRollout(rest, fmt.Errorf("failed to fetch the merge commit of %s#%d: %w", r.PR.Repo, r.PR.Number, err))
+		}
+		merged = append(merged, canaryMerge{PR: r.PR, SHA: details.MergeCommitSHA})
+	}
+
+	if settings.DryRun {
+		fmt.Printf("\n[dry-run] soak for %s, then merge the remaining %d PR(s)\n", settings.Soak, len(rest))
+		return nil
+	}
+
+	fmt.Printf("\nSoaking for %s: watching the default branch CI of %s\n", settings.Soak, strings.Join(reposOf(canary), ", "))
+	err := soak(merged, settings, github.GetCIStatus, func(c canaryMerge) {
+		fmt.Printf("  ✓ %s: default branch CI green at %s\n", c.PR.Repo, shortSHA(c.SHA))
+	})
+	if err != nil {
+		return abortRollout(rest, err)
+	}
+
+	if len(rest) == 0 {
+		fmt.Println("\nRollout complete: no PRs outside the canary repos")
+		return nil
+	}
+
+	fmt.Printf("\nRollout: merging the remaining %d PR(s)\n\n", len(rest))
+	runActions(rest, opts, fn)
+	return nil
+}
+
+// soak polls the CI of the canary merges until each has been green for
+// settings.Soak since the rollout started. A failure, or CI still pending
+// settings.Timeout after the soak, is an error. passed is called once per
+// canary as it completes.
+func soak(merged []canaryMerge, settings soakSettings, ciStatus func(repo, sha string) (*github.CheckStatus, error), passed func(canaryMerge)) error {
+	start := time.Now()
+	deadline := start.Add(settings.Soak + settings.Timeout)
+	interval := settings.Interval
+	if interval <= 0 {
+		interval = executor.DefaultPollInterval
+	}
+
+	watching := merged
+	for {
+		soaked := time.Since(start) >= settings.Soak
+		state := make(map[string]string)
+
+		var still []canaryMerge
+		for _, c := range watching {
+			status, err := ciStatus(c.PR.Repo, c.SHA)
+			if err != nil {
+				// Transient API errors are retried until the deadline
+				state[c.PR.Repo] = fmt.Sprintf("unknown (%v)", err)
+				still = append(still, c)
+				continue
+			}
+
+			switch {
+			case status.State == "pending":
+				state[c.PR.Repo] = "pending"
+				still = append(still, c)
+			case !status.AllPassed:
+				return fmt.Errorf("%s: default branch CI %s at %s after #%d merged", c.PR.Repo, status.State, shortSHA(c.SHA), c.PR.Number)
+			case !soaked:
+				// Green so far, but checks may not have started yet
+				still = append(still, c)
+			default:
+				passed(c)
+			}
+		}
+
+		if len(still) == 0 {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			c := still[0]
+			return fmt.Errorf("%s: default branch CI still %s at %s; gave up after %s", c.PR.Repo, state[c.PR.Repo], shortSHA(c.SHA), settings.Soak+settings.Timeout)
+		}
+
+		watching = still
+		next := interval
+		if !soaked {
+			next = min(next, time.Until(start.Add(settings.Soak)))
+		}
+		time.Sleep(max(min(next, time.Until(deadline)), 0))
+	}
+}
+
+// abortRollout reports the PRs left unmerged by a failed canary
+func abortRollout(rest []types.PR, cause error) error {
+	return fmt.Errorf("canary failed, not merging the remaining %d PR(s): %w", len(rest), cause)
+}
+
+// reposOf returns the distinct repos of the PRs, in order
+func reposOf(prs []types.PR) []string {
+	var repos []string
+	seen := make(map[string]bool)
+	for _, pr := range prs {
+		if !seen[pr.Repo] {
+			seen[pr.Repo] = true
+			repos = append(repos, pr.Repo)
+		}
+	}
+	return repos
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestSplitCanary(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/web", Number: 1},
+		{Repo: "org/canary-app", Number: 2},
+		{Repo: "org/api", Number: 3},
+		{Repo: "org/canary-app", Number: 4},
+	}
+
+	canary, rest := splitCanary(prs, []string{"org/canary-*", "api"})
+	if len(canary) != 3 || canary[0].Number != 2 || canary[1].Number != 3 || canary[2].Number != 4 {
+		t.Errorf("canary = %+v, want #2, #3 and #4", canary)
+	}
+	if len(rest) != 1 || rest[0].Number != 1 {
+		t.Errorf("rest = %+v, want #1", rest)
+	}
+}
+
+func TestSoak(t *testing.T) {
+	merged := []canaryMerge{
+		{PR: types.PR{Repo: "org/app", Number: 1}, SHA: "abcdef1234"},
+		{PR: types.PR{Repo: "org/api", Number: 2}, SHA: "1234abcdef"},
+	}
+	settings := soakSettings{Soak: 20 * time.Millisecond, Timeout: 20 * time.Millisecond, Interval: time.Millisecond}
+
+	tests := []struct {
+		name    string
+		states  map[string][]string // CI states of each repo, in order; the last one repeats
+		wantErr string
+	}{
+		{
+			name:   "green",
+			states: map[string][]string{"org/app": {"success"}, "org/api": {"pending", "success"}},
+		},
+		{
+			name:    "fails during the soak",
+			states:  map[string][]string{"org/app": {"success"}, "org/api": {"success", "pending", "failure"}},
+			wantErr: "org/api: default branch CI failure at 1234abc after #2 merged",
+		},
+		{
+			name:    "still pending",
+			states:  map[string][]string{"org/app": {"success"}, "org/api": {"pending"}},
+			wantErr: "org/api: default branch CI still pending at 1234abc; gave up after 40ms",
+		},
+		{
+			name:    "API errors",
+			states:  map[string][]string{"org/app": {"error: boom"}, "org/api": {"success"}},
+			wantErr: "org/app: default branch CI still unknown (boom)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make(map[string]int)
+			ciStatus := func(repo, sha string) (*github.CheckStatus, error) {
+				states := tt.states[repo]
+				state := states[min(calls[repo], len(states)-1)]
+				calls[repo]++
+				if msg, ok := strings.CutPrefix(state, "error: "); ok {
+					return nil, errors.New(msg)
+				}
+				return &github.CheckStatus{State: state, AllPassed: state == "success"}, nil
+			}
+
+			var passed []string
+			start := time.Now()
+			err := soak(merged, settings, ciStatus, func(c canaryMerge) {
+				passed = append(passed, c.PR.Repo)
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("soak() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("soak() error = %v", err)
+			}
+			if len(passed) != 2 {
+				t.Errorf("passed = %v, want both repos", passed)
+			}
+			if elapsed := time.Since(start); elapsed < settings.Soak {
+				t.Errorf("soak() returned after %s, before the %s soak", elapsed, settings.Soak)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect, change and validate gh-dep settings",
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the effective settings and where each comes from",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Write a setting to a config file",
+	Long: `Write a setting to the user config file (or the repo config file with
+--file repo). List settings take several values; repos and authors may also
+be comma-separated. The file is left unchanged if the result is invalid.
+
+Keys: owner, repos, patterns, merge.method, merge.require-checks,
+overrides.OWNER/REPO.merge.method, overrides.OWNER/REPO.merge.require-checks,
+and profiles.NAME.{owner,repos,bot,authors,label,limit,merge.method,merge.require-checks}`,
+	Example: `  gh dep config set merge.method rebase
+  gh dep config set repos myorg/app,myorg/api
+  gh dep config set overrides.myorg/legacy.merge.require-checks false
+  gh dep config set profiles.frontend.bot renovate --file repo`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from a config file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check every config file and gh config key for errors",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigValidate,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the config file paths, in order of precedence",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPath,
+}
+
+var (
+	configJSON    bool
+	configProfile string
+	configFile    string
+)
+
+func init() {
+	configListCmd.Flags().BoolVar(&configJSON, "json", false, "Output as JSON")
+	configListCmd.Flags().StringVar(&configProfile, "profile", "", "Show the settings with this profile applied")
+	configGetCmd.Flags().BoolVar(&configJSON, "json", false, "Output as JSON, with the source")
+	configGetCmd.Flags().StringVar(&configProfile, "profile", "", "Get the setting with this profile applied")
+	configPathCmd.Flags().BoolVar(&configJSON, "json", false, "Output as JSON")
+	configSetCmd.Flags().StringVar(&configFile, "file", "user", "Config file to write: user or repo")
+	configUnsetCmd.Flags().StringVar(&configFile, "file", "user", "Config file to write: user or repo")
+
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPathCmd)
+}
+
+// effectiveSettings lists the settings with the --profile flag applied
+func effectiveSettings() ([]config.Setting, *config.Config, error) {
+	cfg, err := loadConfig(configProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings := cfg.Settings()
+	if configProfile != "" {
+		settings = append([]config.Setting{{Key: "profile", Value: configProfile, Source: "flag --profile"}}, settings...)
+	}
+	return settings, cfg, nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	settings, _, err := effectiveSettings()
+	if err != nil {
+		return err
+	}
+
+	if configJSON {
+		return printJSON(settings)
+	}
+
+	isTTY := term.IsTerminal(os.Stdout)
+	termWidth, _, _ := term.FromEnv().Size()
+
+	table := tableprinter.New(os.Stdout, isTTY, termWidth)
+	table.AddHeader([]string{"KEY", "VALUE", "SOURCE"})
+	for _, s := range settings {
+		table.AddField(s.Key)
+		table.AddField(formatSetting(s.Value, ", "))
+		table.AddField(s.Source)
+		table.EndRow()
+	}
+
+	return table.Render()
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if err := config.ValidKey(args[0]); err != nil {
+		return err
+	}
+
+	_, cfg, err := effectiveSettings()
+	if err != nil {
+		return err
+	}
+
+	setting, err := cfg.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	if configJSON {
+		return printJSON(setting)
+	}
+
+	if setting.Value != nil {
+		fmt.Println(formatSetting(setting.Value, "\n"))
+	}
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path, err := configFilePath(configFile)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Set(path, args[0], args[1:]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Set %s in %s\n", args[0], path)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	path, err := configFilePath(configFile)
+	if err != nil {
+		return err
+	}
+
+	removed, err := config.Unset(path, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		fmt.Printf("%s is not set in %s\n", args[0], path)
+		return nil
+	}
+	fmt.Printf("Removed %s from %s\n", args[0], path)
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	if _, err := config.Load(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Println("Configuration is valid")
+	return nil
+}
+
+// configPath describes a config file for config path
+type configPath struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	userPath, err := config.UserConfigPath()
+	if err != nil {
+		return err
+	}
+
+	paths := []configPath{{Name: "user", Path: userPath}}
+	if repoPath := config.RepoConfigPath(); repoPath != "" {
+		paths = append(paths, configPath{Name: "repo", Path: repoPath})
+	}
+	for i := range paths {
+		_, err := os.Stat(paths[i].Path)
+		paths[i].Exists = err == nil
+	}
+
+	if configJSON {
+		return printJSON(paths)
+	}
+
+	for _, p := range paths {
+		status := ""
+		if !p.Exists {
+			status = " (not created)"
+		}
+		fmt.Printf("%s\t%s%s\n", p.Name, p.Path, status)
+	}
+	return nil
+}
+
+// configFilePath resolves --file to the path of a config file
+func configFilePath(file string) (string, error) {
+	switch file {
+	case "user":
+		return config.UserConfigPath()
+	case "repo":
+		path := config.RepoConfigPath()
+		if path == "" {
+			return "", fmt.Errorf("not in a git repository; --file repo writes .github/gh-dep.yml of the current repository")
+		}
+		return path, nil
+	default:
+		return "", fmt.Errorf("invalid value for --file: %q (must be 'user' or 'repo')", file)
+	}
+}
+
+// formatSetting renders a setting value, joining lists with sep
+func formatSetting(value any, sep string) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, sep)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/jackchuka/gh-dep/internal/ui"
+)
+
+// runActions performs fn on the PRs through the executor, printing each
+// result as it finishes, when a PR starts waiting, and a summary at the end
+func runActions(prs []types.PR, opts executor.Options, fn executor.Func) executor.Summary {
+	display := ui.New(prs, false)
+
+	waiting := make(map[string]bool)
+	opts.OnEvent = func(e executor.Event) {
+		switch e.Kind {
+		case executor.EventFinished:
+			printResult(display, e.Result)
+		case executor.EventPending:
+			key := fmt.Sprintf("%s#%d", e.PR.Repo, e.PR.Number)
+			if !waiting[key] {
+				waiting[key] = true
+				display.PrintAction("waiting", e.PR, e.Result.Err.Error())
+			}
+		}
+	}
+
+	summary := executor.Run(prs, fn, opts)
+
+	fmt.Printf("\nSummary: %s\n", summary)
+	return summary
+}
+
+func printResult(display *ui.UI, r executor.Result) {
+	switch r.Status {
+	case executor.StatusDone:
+		if r.Detail != "" {
+			display.PrintAction(r.Action, r.PR, r.Detail)
+		} else {
+			display.PrintAction(r.Action, r.PR)
+		}
+	case executor.StatusSkipped:
+		display.PrintAction("skipped", r.PR, r.Err.Error())
+	default:
+		display.PrintError(r.Action, r.PR, r.Err)
+	}
+}
@@ -10,26 +10,50 @@ import (
 
 var groupsCmd = &cobra.Command{
 	Use:   "groups",
-	Short: "Show cached groups from the last list --group",
+	Short: "Show cached groups from list --group",
 	RunE:  runGroups,
 }
 
-var groupsJSON bool
+var (
+	groupsJSON   bool
+	groupsScope  string
+	groupsScopes bool
+)
 
 func init() {
 	groupsCmd.Flags().BoolVar(&groupsJSON, "json", false, "Output as JSON")
+	groupsCmd.Flags().StringVar(&groupsScope, "scope", "", "Cached scope to show (default: the most recent list --group)")
+	groupsCmd.Flags().BoolVar(&groupsScopes, "scopes", false, "List the cached scopes instead of groups")
 }
 
 func runGroups(cmd *cobra.Command, args []string) error {
+	if groupsScopes {
+		return runGroupsScopes()
+	}
+
+	entry, err := loadCachedEntry(groupsScope, 0)
+	if err != nil {
+		return err
+	}
+
+	display := ui.NewFromGroups(entry.Groups, groupsJSON)
+	return display.DisplayGroups(entry.Groups)
+}
+
+func runGroupsScopes() error {
 	c, err := cache.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load cache: %w", err)
 	}
 
-	if c == nil || len(c.Groups) == 0 {
+	entries, err := cache.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load cache: %w", err)
+	}
+	if len(entries) == 0 {
 		return fmt.Errorf("no cached groups found. Run 'gh dep list --group' first")
 	}
 
-	display := ui.NewFromGroups(c.Groups, groupsJSON)
-	return display.DisplayGroups(c.Groups)
+	display := ui.New(nil, groupsJSON)
+	return display.DisplayScopes(entries, c.Last)
 }
@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jackchuka/gh-dep/internal/cache"
-	"github.com/jackchuka/gh-dep/internal/config"
 	"github.com/jackchuka/gh-dep/internal/github"
 	"github.com/jackchuka/gh-dep/internal/types"
 	"github.com/jackchuka/gh-dep/internal/ui"
@@ -13,7 +13,7 @@ import (
 
 var listCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List dependency PRs, optionally grouped by package@version",
+	Short: "List dependency PRs, optionally grouped by package@version or another strategy",
 	RunE:  runList,
 }
 
@@ -28,46 +28,90 @@ var (
 	listReviewRequested string
 	listArchived        bool
 	listBot             string
+	listByDirectory     bool
+	listGroupBy         string
+	listHeadPrefix      string
+	listDiscoverLabel   string
+	listExcludeLabel    string
+	listExcludeRepo     string
+	listTopic           string
+	listByBranch        bool
+	listProfile         string
 )
 
 func init() {
-	listCmd.Flags().BoolVar(&listGroup, "group", false, "Group PRs by package@version")
+	listCmd.Flags().BoolVar(&listGroup, "group", false, "Group PRs (by package@version unless --group-by is set) and cache the groups")
+	listCmd.Flags().StringVar(&listGroupBy, "group-by", "package-version", "Grouping strategy: package-version, package, package-major, ecosystem, repo, update-type, or group-name")
+	listCmd.Flags().BoolVar(&listByDirectory, "by-directory", false, "Split groups by the directory the update applies to (package@version:/dir)")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output as JSON")
 
+	listCmd.Flags().StringVar(&listProfile, "profile", "", "Configured profile to use for scope, authors, label, and limit (flags still override)")
 	listCmd.Flags().IntVar(&listLimit, "limit", 200, "Max PRs to fetch per repo")
 
 	// additional filters
 	listCmd.Flags().StringVarP(&listRepo, "repo", "R", "", "Target repo(s), comma-separated")
 	listCmd.Flags().StringVar(&listLabel, "label", "", "PR label to filter")
 	listCmd.Flags().StringVar(&listAuthor, "author", "", "PR author to filter")
-	listCmd.Flags().StringVar(&listBot, "bot", "all", "Dependency bot to target: all, dependabot, or renovate (overridden by --author)")
+	listCmd.Flags().StringVar(&listBot, "bot", "all", "Dependency bot(s) to target: all, or comma-separated names such as dependabot, renovate, snyk, pre-commit-ci, depfu (overridden by --author)")
 	listCmd.Flags().StringVar(&listOwner, "owner", "", "Target owner (user or org)")
 	listCmd.Flags().StringVar(&listReviewRequested, "review-requested", "", "Filter PRs by review requested from user or team (e.g., '@me' or 'username')")
 	listCmd.Flags().BoolVar(&listArchived, "archived", false, "Include PRs from archived repositories")
+	listCmd.Flags().StringVar(&listTopic, "topic", "", "Only target repos tagged with one of these topic(s), comma-separated")
+
+	// exclusions
+	listCmd.Flags().StringVar(&listExcludeLabel, "exclude-label", "", "Skip PRs carrying any of these label(s), comma-separated (replaces the configured exclude.labels)")
+	listCmd.Flags().StringVar(&listExcludeRepo, "exclude-repo", "", "Skip these repo(s): OWNER/REPO, REPO, or globs, comma-separated (replaces the configured exclude.repos)")
+
+	// discovery beyond bot authors
+	listCmd.Flags().BoolVar(&listByBranch, "by-branch", false, "Also discover PRs by the head branch prefix of the selected bots (e.g., renovate/), whoever opened them")
+	listCmd.Flags().StringVar(&listHeadPrefix, "head-prefix", "", "Also discover PRs whose head branch starts with these prefix(es), comma-separated")
+	listCmd.Flags().StringVar(&listDiscoverLabel, "discover-label", "", "Also discover PRs carrying these label(s), comma-separated, whoever opened them")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	cfg, err := loadConfig(listProfile)
+	if err != nil {
+		return err
+	}
+
+	groupBy, err := github.ParseGroupBy(listGroupBy)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	label := listLabel
-	authors, err := resolveAuthors(cmd, listAuthor, listBot)
+	label := resolveLabel(cmd, listLabel, cfg)
+	authors, err := resolveAuthors(cmd, listAuthor, listBot, cfg)
 	if err != nil {
 		return err
 	}
 
 	owner, repos := resolveScope(cmd, listRepo, listOwner, cfg)
 
+	headPrefixes, err := resolveHeadPrefixes(listByBranch, listHeadPrefix, resolveBot(cmd, listBot, cfg), cfg)
+	if err != nil {
+		return err
+	}
+
+	excludeLabels, excludeRepos := resolveExclusions(cmd, listExcludeLabel, listExcludeRepo, cfg)
+
 	searchParams := github.SearchParams{
 		Owner:           owner,
 		Repos:           repos,
 		Label:           label,
 		Authors:         authors,
-		Limit:           listLimit,
+		Limit:           resolveLimit(cmd, listLimit, cfg),
 		ReviewRequested: listReviewRequested,
 		Archived:        listArchived,
+		HeadPrefixes:    headPrefixes,
+		DiscoverLabels:  cleanRepos(listDiscoverLabel),
+		ExcludeLabels:   excludeLabels,
+		ExcludeRepos:    excludeRepos,
+		Topics:          cleanRepos(listTopic),
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return err
 	}
 
 	allPRs, err := github.SearchPRs(searchParams)
@@ -81,13 +125,23 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	if listGroup {
-		groups := github.GroupPRs(allPRs, cfg.GetPatterns())
+		groups := github.GroupPRs(allPRs, github.GroupOptions{
+			Patterns:    cfg.GetPatterns(),
+			Bots:        registry,
+			Strategy:    groupBy,
+			ByDirectory: listByDirectory,
+		})
 
 		// Cache the groups
-		c := &types.Cache{
-			Groups: groups,
+		entry := &types.CacheEntry{
+			Scope:       cache.ScopeKey(owner, repos),
+			FetchedAt:   time.Now(),
+			Params:      searchParams.Record(),
+			Groups:      groups,
+			GroupBy:     string(groupBy),
+			ByDirectory: listByDirectory,
 		}
-		if err := cache.Save(c); err != nil {
+		if err := cache.Save(entry); err != nil {
 			return fmt.Errorf("failed to save cache: %w", err)
 		}
 
@@ -96,6 +150,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		return display.DisplayGroups(groups)
 	}
 
+	github.AnnotatePRs(allPRs, github.GroupOptions{Patterns: cfg.GetPatterns(), Bots: registry})
 	display := ui.New(allPRs, listJSON)
 	return display.DisplayList(allPRs)
 }
@@ -1,34 +1,59 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
 	"github.com/jackchuka/gh-dep/internal/github"
-	"github.com/jackchuka/gh-dep/internal/ui"
+	"github.com/jackchuka/gh-dep/internal/train"
+	"github.com/jackchuka/gh-dep/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var mergeCmd = &cobra.Command{
 	Use:   "merge",
-	Short: "Bulk merge all PRs in a group",
+	Short: "Bulk merge the PRs in one or more groups",
 	RunE:  runMerge,
 }
 
 var (
-	mergeGroup         string
+	mergeSelect        groupSelection
+	mergeScope         string
+	mergeMaxAge        time.Duration
 	mergeDryRun        bool
 	mergeMethod        string
 	mergeRequireChecks bool
+	mergeDeleteBranch  bool
+	mergeProfile       string
+	mergeConcurrency   int
+	mergeWait          bool
+	mergeTrain         bool
+	mergeTimeout       time.Duration
+	mergeCanary        string
+	mergeSoak          time.Duration
+	mergeAtomic        bool
 )
 
 func init() {
-	mergeCmd.Flags().StringVar(&mergeGroup, "group", "", "Group key (package@version)")
-	_ = mergeCmd.MarkFlagRequired("group")
+	mergeSelect.addFlags(mergeCmd)
+	mergeCmd.Flags().StringVar(&mergeScope, "scope", "", "Cached scope to use (default: the profile's scope, else the most recent list --group; see groups --scopes)")
+	mergeCmd.Flags().StringVar(&mergeProfile, "profile", "", "Configured profile whose scope selects the cached groups and whose merge settings apply (flags still override)")
+	mergeCmd.Flags().DurationVar(&mergeMaxAge, "max-age", 0, "Refuse to act on cached groups older than this (e.g., 30m, 2h)")
 
 	mergeCmd.Flags().BoolVar(&mergeDryRun, "dry-run", false, "Print actions without executing")
 	mergeCmd.Flags().StringVar(&mergeMethod, "method", "squash", "Merge method: merge, squash, or rebase")
 	mergeCmd.Flags().BoolVar(&mergeRequireChecks, "require-checks", true, "Require CI checks to pass")
+	mergeCmd.Flags().BoolVar(&mergeDeleteBranch, "delete-branch", false, "Delete the head branch after merging")
+	mergeCmd.Flags().BoolVar(&mergeWait, "wait", false, "Keep polling PRs whose CI is pending and merge each as soon as it passes")
+	mergeCmd.Flags().BoolVar(&mergeTrain, "train", false, "Merge one PR per repo at a time, rebasing each before its merge and waiting for its CI; a failure stops the repo's train")
+	mergeCmd.Flags().DurationVar(&mergeTimeout, "timeout", 30*time.Minute, "With --wait or --train, how long to wait for pending CI or rebases before giving up; with --canary, how long the canaries' CI may stay pending after the soak")
+	mergeCmd.Flags().StringVar(&mergeCanary, "canary", "", "Merge into these repo(s) first (OWNER/REPO, REPO, or globs, comma-separated) and into the rest only once their default branch CI passes")
+	mergeCmd.Flags().DurationVar(&mergeSoak, "soak", 30*time.Minute, "With --canary, how long the canary repos' default branch CI must stay green before merging into the rest")
+	mergeCmd.Flags().BoolVar(&mergeAtomic, "atomic", false, "Check every PR of each group first (CI, mergeability, reviews) and merge none of a group's PRs unless all of them can be merged")
+	mergeCmd.Flags().IntVar(&mergeConcurrency, "concurrency", executor.DefaultConcurrency, "Repos to merge into at once; PRs of a repo are merged one at a time")
 }
 
 func runMerge(cmd *cobra.Command, args []string) error {
@@ -36,59 +61,158 @@ func runMerge(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid merge method: %s (must be 'merge', 'squash', or 'rebase')", mergeMethod)
 	}
 
-	c, err := cache.Load()
+	canaryRepos := cleanRepos(mergeCanary)
+	if cmd.Flags().Changed("soak") && len(canaryRepos) == 0 {
+		return errors.New("--soak requires --canary")
+	}
+
+	cfg, err := loadConfig(mergeProfile)
+	if err != nil {
+		return err
+	}
+
+	entry, err := loadCachedEntry(resolveCachedScope(mergeScope, cfg), mergeMaxAge)
 	if err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+		return err
 	}
 
-	if c == nil || len(c.Groups) == 0 {
-		return fmt.Errorf("no cached groups found. Run 'gh dep list --group' first")
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		return err
+	}
+
+	groups, err := mergeSelect.selectGroups(entry, checks.opts)
+	if err != nil {
+		return err
+	}
+	var prs []types.PR
+	for _, g := range groups {
+		prs = append(prs, g.PRs...)
+	}
+	if len(prs) == 0 {
+		fmt.Println("No cached PRs match the selection")
+		return nil
 	}
 
-	prs, ok := c.Groups[mergeGroup]
-	if !ok {
-		return fmt.Errorf("group '%s' not found in cache", mergeGroup)
+	opts := executor.Options{Concurrency: mergeConcurrency, Train: mergeTrain}
+	if mergeWait || mergeTrain {
+		opts.Wait = mergeTimeout
 	}
 
-	display := ui.New(prs, false)
+	settingsFor := mergeSettingsFor(cmd, cfg, checks)
+
+	mergeOne := func(pr types.PR) executor.Result {
+		method, requireChecks, deleteBranch, err := settingsFor(pr)
+		if err != nil {
+			return executor.Skip(pr, "merge", err)
+		}
+
+		merge := func(pr types.PR) executor.Result {
+			if err := github.MergeViaPR(pr.Repo, pr.Number, method); err != nil {
+				recordState(pr, types.StatusFailed, fmt.Sprintf("merge: %v", err))
+				return executor.Fail(pr, "merge", err)
+			}
+
+			recordState(pr, types.StatusMerged, "")
+			detail := "via API, " + method
 
-	for _, pr := range prs {
-		if mergeRequireChecks {
-			headSHA := pr.HeadSHA
-			if headSHA == "" {
-				sha, err := github.GetPRHead(pr.Repo, pr.Number)
-				if err != nil {
-					display.PrintAction("skipped", pr, fmt.Sprintf("failed to fetch PR head: %v", err))
-					continue
+			if deleteBranch {
+				if err := github.DeleteHeadBranch(pr.Repo, pr.Number); err != nil {
+					detail += fmt.Sprintf(" (failed to delete branch: %v)", err)
 				}
-				headSHA = sha
+			}
+			return executor.Done(pr, "merge", detail)
+		}
+
+		if mergeTrain {
+			if mergeDryRun {
+				return executor.Done(pr, "[dry-run] merge", method+", train")
+			}
+			return train.Step(pr, train.Options{Bots: checks.opts.Bots, RequireChecks: requireChecks, Merge: merge})
+		}
+
+		if requireChecks {
+			// The cached head may be stale, so always check the current one
+			details, err := github.GetPR(pr.Repo, pr.Number)
+			if err != nil {
+				return executor.Skip(pr, "merge", fmt.Errorf("failed to fetch PR head: %w", err))
+			}
+			// Merged or closed outside gh-dep since the groups were cached
+			if details.Merged {
+				recordState(pr, types.StatusMerged, "")
+				return executor.Skip(pr, "merge", errors.New("already merged"))
+			}
+			if details.State == "closed" {
+				recordState(pr, types.StatusClosed, "")
+				return executor.Skip(pr, "merge", errors.New("closed"))
 			}
 
-			status, err := github.GetCIStatus(pr.Repo, headSHA)
+			status, err := github.GetCIStatus(pr.Repo, details.HeadSHA)
 			if err != nil {
-				display.PrintAction("skipped", pr, fmt.Sprintf("failed to check CI status: %v", err))
-				continue
+				return executor.Skip(pr, "merge", fmt.Errorf("failed to check CI status: %w", err))
 			}
 
+			if status.State == "pending" {
+				// Retried with --wait until the checks finish
+				return executor.Pending(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
+			}
 			if !status.AllPassed {
-				display.PrintAction("skipped", pr, fmt.Sprintf("CI checks not passing (state: %s)", status.State))
-				continue
+				return executor.Skip(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
 			}
 		}
 
 		if mergeDryRun {
-			display.PrintAction("[dry-run] merge", pr)
-			continue
+			return executor.Done(pr, "[dry-run] merge", method)
 		}
 
-		mergeErr := github.MergeViaPR(pr.Repo, pr.Number, mergeMethod)
-		if mergeErr != nil {
-			display.PrintError("merge", pr, mergeErr)
-			continue
+		return merge(pr)
+	}
+
+	if mergeAtomic {
+		prs = atomicSelection(groups, mergeConcurrency, atomicCheck(settingsFor, mergeTrain))
+		if len(prs) == 0 {
+			fmt.Println("No group can be merged in full")
+			return nil
 		}
+	}
 
-		display.PrintAction("merge", pr, "via API")
+	if len(canaryRepos) > 0 {
+		// --timeout also bounds how long the canaries' CI may stay pending after the soak
+		settings := soakSettings{Soak: mergeSoak, Timeout: mergeTimeout, DryRun: mergeDryRun}
+		return runCanary(prs, canaryRepos, settings, opts, mergeOne)
 	}
 
+	runActions(prs, opts, mergeOne)
 	return nil
 }
+
+// mergeSettings resolves the merge settings of a PR, or why it's skipped
+type mergeSettings func(pr types.PR) (method string, requireChecks, deleteBranch bool, skip error)
+
+// mergeSettingsFor returns the merge settings of PRs from the flags, the
+// config's per-repo settings and the policy
+func mergeSettingsFor(cmd *cobra.Command, cfg *config.Config, checks *policyCheck) mergeSettings {
+	return func(pr types.PR) (method string, requireChecks, deleteBranch bool, skip error) {
+		// PRs merged or closed by an earlier run are left alone
+		if pr.State.Done() {
+			return "", false, false, fmt.Errorf("already %s", pr.State.Status)
+		}
+
+		if err := cfg.CheckAllowed(pr.Repo, config.ActionMerge); err != nil {
+			return "", false, false, err
+		}
+
+		method, requireChecks = resolveMerge(cmd, "method", mergeMethod, mergeRequireChecks, cfg, pr.Repo)
+		deleteBranch = resolveDeleteBranch(cmd, mergeDeleteBranch, cfg, pr.Repo)
+
+		rule := checks.evaluate(pr)
+		if rule.RequireChecks != nil {
+			// --require-checks can only add to what the policy asks for
+			requireChecks = *rule.RequireChecks || (cmd.Flags().Changed("require-checks") && mergeRequireChecks)
+		}
+		if err := rule.CheckMerge(approvalsOf(pr)); err != nil {
+			return "", false, false, err
+		}
+		return method, requireChecks, deleteBranch, nil
+	}
+}
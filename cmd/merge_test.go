@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestMergeSettingsPolicyRequireChecks(t *testing.T) {
+	required, waived := true, false
+	cfg := &config.Config{Policy: policy.Policy{Rules: []policy.Rule{
+		{Name: "strict", Match: policy.Match{Repos: []string{"org/strict"}}, RequireChecks: &required},
+		{Name: "flaky", Match: policy.Match{Repos: []string{"org/flaky"}}, RequireChecks: &waived},
+	}}}
+	if err := cfg.Policy.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		t.Fatalf("newPolicyCheck() error = %v", err)
+	}
+
+	saved := mergeRequireChecks
+	t.Cleanup(func() { mergeRequireChecks = saved })
+
+	tests := []struct {
+		repo string
+		flag string // explicit --require-checks value, "" when not given
+		want bool
+	}{
+		{"org/strict", "", true},
+		{"org/strict", "false", true},
+		{"org/flaky", "", false},
+		{"org/flaky", "true", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo+" "+tt.flag, func(t *testing.T) {
+			c := newTestCommand()
+			c.Flags().String("method", "squash", "")
+			c.Flags().BoolVar(&mergeRequireChecks, "require-checks", true, "")
+			if tt.flag != "" {
+				if err := c.Flags().Set("require-checks", tt.flag); err != nil {
+					t.Fatalf("Set failed: %v", err)
+				}
+			}
+
+			_, requireChecks, _, skip := mergeSettingsFor(c, cfg, checks)(types.PR{Repo: tt.repo, Number: 1})
+			if skip != nil || requireChecks != tt.want {
+				t.Errorf("requireChecks = %t, %v; want %t", requireChecks, skip, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var patternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Test and explain the title patterns used for grouping",
+}
+
+var patternsTestCmd = &cobra.Command{
+	Use:   "test <title>",
+	Short: "Show how every pattern handles a PR title",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPatternsTest,
+}
+
+var patternsExplainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show how every pattern handles the titles of a cached group",
+	RunE:  runPatternsExplain,
+}
+
+var patternsUnmatchedCmd = &cobra.Command{
+	Use:   "unmatched",
+	Short: "List open dependency PR titles that no pattern can parse",
+	RunE:  runPatternsUnmatched,
+}
+
+var (
+	patternsTestBot   string
+	patternsGroup     string
+	patternsScope     string
+	patternsRepo      string
+	patternsOwner     string
+	patternsLabel     string
+	patternsAuthor    string
+	patternsBot       string
+	patternsLimit     int
+	patternsArchived  bool
+	patternsReviewReq string
+	patternsExclLabel string
+	patternsExclRepo  string
+	patternsTopic     string
+)
+
+func init() {
+	patternsTestCmd.Flags().StringVar(&patternsTestBot, "bot", "", "Also try the title patterns of this bot (name or login)")
+
+	patternsExplainCmd.Flags().StringVar(&patternsGroup, "group", "", "Group key from list --group (e.g., unknown@unknown)")
+	_ = patternsExplainCmd.MarkFlagRequired("group")
+	patternsExplainCmd.Flags().StringVar(&patternsScope, "scope", "", "Cached scope to use (default: the most recent list --group)")
+
+	patternsUnmatchedCmd.Flags().IntVar(&patternsLimit, "limit", 200, "Max PRs to fetch per repo")
+	patternsUnmatchedCmd.Flags().StringVarP(&patternsRepo, "repo", "R", "", "Target repo(s), comma-separated")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsOwner, "owner", "", "Target owner (user or org)")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsLabel, "label", "", "PR label to filter")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsAuthor, "author", "", "PR author to filter")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsBot, "bot", "all", "Dependency bot(s) to target: all, or comma-separated names such as dependabot, renovate, snyk, pre-commit-ci, depfu (overridden by --author)")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsReviewReq, "review-requested", "", "Filter PRs by review requested from user or team (e.g., '@me' or 'username')")
+	patternsUnmatchedCmd.Flags().BoolVar(&patternsArchived, "archived", false, "Include PRs from archived repositories")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsTopic, "topic", "", "Only target repos tagged with one of these topic(s), comma-separated")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsExclLabel, "exclude-label", "", "Skip PRs carrying any of these label(s), comma-separated (replaces the configured exclude.labels)")
+	patternsUnmatchedCmd.Flags().StringVar(&patternsExclRepo, "exclude-repo", "", "Skip these repo(s): OWNER/REPO, REPO, or globs, comma-separated (replaces the configured exclude.repos)")
+
+	patternsCmd.AddCommand(patternsTestCmd)
+	patternsCmd.AddCommand(patternsExplainCmd)
+	patternsCmd.AddCommand(patternsUnmatchedCmd)
+}
+
+func runPatternsTest(cmd *cobra.Command, args []string) error {
+	// Read without validating so invalid patterns are reported, not fatal
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	customPatterns := cfg.GetPatterns()
+	if patternsTestBot != "" {
+		registry, err := cfg.BotRegistry()
+		if err != nil {
+			return err
+		}
+		b, ok := registry.Get(patternsTestBot)
+		if !ok {
+			return fmt.Errorf("unknown bot: %q (expected one of %s)", patternsTestBot, strings.Join(registry.Names(), ", "))
+		}
+		customPatterns = append(customPatterns, b.TitlePatterns...)
+	}
+
+	printExplanation(args[0], customPatterns)
+	return nil
+}
+
+func runPatternsExplain(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Read()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	entry, err := loadCachedEntry(patternsScope, 0)
+	if err != nil {
+		return err
+	}
+
+	prs, err := findCachedGroup(entry, patternsGroup)
+	if err != nil {
+		return err
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return err
+	}
+	opts := github.GroupOptions{Patterns: cfg.GetPatterns(), Bots: registry}
+
+	// Titles repeat across repos; explain each distinct one once
+	seen := make(map[string]bool)
+	for _, pr := range prs {
+		if seen[pr.Title] {
+			continue
+		}
+		if len(seen) > 0 {
+			fmt.Println()
+		}
+		seen[pr.Title] = true
+
+		fmt.Printf("%s #%d\n", pr.Repo, pr.Number)
+		printExplanation(pr.Title, opts.PatternsFor(pr))
+	}
+
+	return nil
+}
+
+func runPatternsUnmatched(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	authors, err := resolveAuthors(cmd, patternsAuthor, patternsBot, cfg)
+	if err != nil {
+		return err
+	}
+	owner, repos := resolveScope(cmd, patternsRepo, patternsOwner, cfg)
+	excludeLabels, excludeRepos := resolveExclusions(cmd, patternsExclLabel, patternsExclRepo, cfg)
+
+	allPRs, err := github.SearchPRs(github.SearchParams{
+		Owner:           owner,
+		Repos:           repos,
+		Label:           patternsLabel,
+		Authors:         authors,
+		Limit:           patternsLimit,
+		ReviewRequested: patternsReviewReq,
+		Archived:        patternsArchived,
+		ExcludeLabels:   excludeLabels,
+		ExcludeRepos:    excludeRepos,
+		Topics:          cleanRepos(patternsTopic),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search PRs: %w", err)
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return err
+	}
+	opts := github.GroupOptions{Patterns: cfg.GetPatterns(), Bots: registry}
+
+	// Collect distinct unmatched titles with the PRs that use them
+	unmatched := make(map[string][]string)
+	for _, pr := range allPRs {
+		update := parser.ParseTitle(pr.Title, opts.PatternsFor(pr))
+		if update.Unknown() {
+			unmatched[pr.Title] = append(unmatched[pr.Title], fmt.Sprintf("%s#%d", pr.Repo, pr.Number))
+		}
+	}
+
+	if len(unmatched) == 0 {
+		fmt.Println("All dependency PR titles match a pattern")
+		return nil
+	}
+
+	titles := make([]string, 0, len(unmatched))
+	for title := range unmatched {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+
+	for i, title := range titles {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s\n", title)
+		fmt.Printf("  PRs:        %s\n", strings.Join(unmatched[title], ", "))
+		if suggestion := parser.SuggestPattern(title); suggestion != "" {
+			fmt.Printf("  Suggestion: %s\n", suggestion)
+		} else {
+			fmt.Printf("  Suggestion: none (no version found in title)\n")
+		}
+	}
+
+	return nil
+}
+
+// printExplanation prints each pattern's outcome for a title, followed by
+// the group key ParseTitle settles on
+func printExplanation(title string, customPatterns []string) {
+	fmt.Printf("Title: %s\n", title)
+
+	used := false
+	for i, result := range parser.Explain(title, customPatterns) {
+		status := "no match"
+		switch {
+		case result.Err != nil:
+			status = "invalid"
+		case result.Matched && !used:
+			// ParseTitle stops at the first match
+			status = "matched (used)"
+			used = true
+		case result.Matched:
+			status = "matched"
+		}
+
+		fmt.Printf("  %2d. [%s] %s: %s\n", i+1, result.Source, status, result.Pattern)
+		if result.Err != nil {
+			fmt.Printf("      error: %v\n", result.Err)
+		}
+		if result.Matched {
+			fmt.Printf("      %s\n", formatFields(result.Fields))
+		}
+	}
+
+	update := parser.ParseTitle(title, customPatterns)
+	fmt.Printf("Result: %s\n", update.GroupKey())
+	if update.Unknown() {
+		if suggestion := parser.SuggestPattern(title); suggestion != "" {
+			fmt.Printf("Suggestion: %s\n", suggestion)
+		}
+	}
+}
+
+// formatFields renders captured fields in a stable order
+func formatFields(fields map[string]string) string {
+	order := []string{parser.FieldPackage, parser.FieldFrom, parser.FieldTo, parser.FieldEcosystem, parser.FieldDirectory}
+
+	var parts []string
+	for _, name := range order {
+		if value, ok := fields[name]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
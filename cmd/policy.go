@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// policyCheck evaluates the configured policy against PRs, parsing titles
+// the same way grouping does
+type policyCheck struct {
+	policy *policy.Policy
+	opts   github.GroupOptions
+}
+
+func newPolicyCheck(cfg *config.Config) (*policyCheck, error) {
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	return &policyCheck{
+		policy: &cfg.Policy,
+		opts:   github.GroupOptions{Patterns: cfg.GetPatterns(), Bots: registry},
+	}, nil
+}
+
+func (c *policyCheck) evaluate(pr types.PR) policy.Result {
+	return c.policy.Evaluate(pr, c.opts.ParseUpdate(pr))
+}
+
+// approvalsOf counts a PR's approvals for policy.Result.CheckMerge
+func approvalsOf(pr types.PR) func() (int, error) {
+	return func() (int, error) {
+		return github.CountApprovals(pr.Repo, pr.Number)
+	}
+}
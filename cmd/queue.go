@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/tableprinter"
+	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"github.com/jackchuka/gh-dep/internal/tui"
+	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/jackchuka/gh-dep/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Manage actions queued in offline mode (gh dep --from-cache)",
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show queued actions",
+	RunE:  runQueueList,
+}
+
+var queueRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Revalidate and run queued actions",
+	RunE:  runQueueRun,
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Discard all queued actions",
+	RunE:  runQueueClear,
+}
+
+var queueDryRun bool
+
+func init() {
+	queueRunCmd.Flags().BoolVar(&queueDryRun, "dry-run", false, "Revalidate and print actions without executing")
+
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueRunCmd)
+	queueCmd.AddCommand(queueClearCmd)
+}
+
+func runQueueList(cmd *cobra.Command, args []string) error {
+	queued, err := cache.LoadQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load queue: %w", err)
+	}
+
+	if len(queued) == 0 {
+		fmt.Println("No queued actions")
+		return nil
+	}
+
+	isTTY := term.IsTerminal(os.Stdout)
+	termWidth, _, _ := term.FromEnv().Size()
+
+	table := tableprinter.New(os.Stdout, isTTY, termWidth)
+	table.AddHeader([]string{"REPO", "PR", "ACTION", "QUEUED", "TITLE"})
+	for _, action := range queued {
+		table.AddField(action.Repo)
+		table.AddField(fmt.Sprintf("#%d", action.Number))
+		table.AddField(describeQueued(action))
+		table.AddField(ui.FormatAge(time.Since(action.QueuedAt)) + " ago")
+		table.AddField(action.Title)
+		table.EndRow()
+	}
+
+	return table.Render()
+}
+
+func runQueueClear(cmd *cobra.Command, args []string) error {
+	if err := cache.ClearQueue(); err != nil {
+		return fmt.Errorf("failed to clear queue: %w", err)
+	}
+	fmt.Println("Cleared queued actions")
+	return nil
+}
+
+func runQueueRun(cmd *cobra.Command, args []string) error {
+	queued, err := cache.LoadQueue()
+	if err != nil {
+		return fmt.Errorf("failed to load queue: %w", err)
+	}
+
+	if len(queued) == 0 {
+		fmt.Println("No queued actions")
+		return nil
+	}
+
+	cfg, err := loadConfig("")
+	if err != nil {
+		return err
+	}
+
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		return err
+	}
+
+	var prs []types.PR
+	for _, action := range queued {
+		prs = append(prs, queuedPR(action))
+	}
+	display := ui.New(prs, false)
+
+	for _, action := range queued {
+		pr := queuedPR(action)
+		done := runQueued(display, cfg, checks, action, pr)
+		if done && !queueDryRun {
+			if err := cache.UpdateQueue(func(q []types.QueuedAction) []types.QueuedAction {
+				return cache.RemoveQueued(q, action.Repo, action.Number)
+			}); err != nil {
+				return fmt.Errorf("failed to update queue: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runQueued revalidates a queued action against the PR's current state and
+// runs it. Returns whether the action is finished with and can be dropped
+// from the queue; actions waiting on CI or approvals, or that failed, stay
+// queued.
+func runQueued(display *ui.UI, cfg *config.Config, checks *policyCheck, action types.QueuedAction, pr types.PR) bool {
+	details, err := github.GetPR(pr.Repo, pr.Number)
+	if err != nil {
+		display.PrintAction("skipped", pr, fmt.Sprintf("failed to fetch PR: %v", err))
+		return false
+	}
+
+	if details.Merged {
+		display.PrintAction("dropped", pr, "already merged")
+		recordState(pr, types.StatusMerged, "")
+		return true
+	}
+	if details.State == "closed" {
+		display.PrintAction("dropped", pr, "closed")
+		recordState(pr, types.StatusClosed, "")
+		return true
+	}
+	if action.HeadSHA != "" && details.HeadSHA != action.HeadSHA {
+		display.PrintAction("dropped", pr, fmt.Sprintf("head changed since it was queued (%s → %s); review it again", shortSHA(action.HeadSHA), shortSHA(details.HeadSHA)))
+		return true
+	}
+
+	plan := planQueued(cfg, checks, action, pr)
+	if plan.reason != "" {
+		if plan.drop {
+			display.PrintAction("dropped", pr, plan.reason)
+		} else {
+			display.PrintAction("skipped", pr, plan.reason+"; left in queue")
+		}
+		return plan.drop
+	}
+	approve, merge, requireChecks := plan.approve, plan.merge, plan.requireChecks
+
+	if merge && requireChecks {
+		status, err := github.GetCIStatus(pr.Repo, details.HeadSHA)
+		if err != nil {
+			display.PrintAction("skipped", pr, fmt.Sprintf("failed to check CI status: %v", err))
+			return false
+		}
+		if !status.AllPassed {
+			display.PrintAction("skipped", pr, fmt.Sprintf("CI checks not passing (state: %s); left in queue", status.State))
+			return false
+		}
+	}
+
+	if queueDryRun {
+		display.PrintAction("[dry-run] "+describeQueued(action), pr)
+		return false
+	}
+
+	if approve {
+		if err := github.ApprovePR(pr.Repo, pr.Number); err != nil {
+			display.PrintError("approve", pr, err)
+			recordState(pr, types.StatusFailed, fmt.Sprintf("approve: %v", err))
+			return false
+		}
+		display.PrintAction("approve", pr)
+		recordState(pr, types.StatusApproved, "")
+	}
+
+	if merge {
+		if err := github.MergeViaPR(pr.Repo, pr.Number, action.MergeMethod); err != nil {
+			display.PrintError("merge", pr, err)
+			recordState(pr, types.StatusFailed, fmt.Sprintf("merge: %v", err))
+			if approve {
+				// Don't approve again on the next run
+				requeueAsMerge(action)
+			}
+			return false
+		}
+		display.PrintAction("merge", pr, "via API, "+action.MergeMethod)
+		recordState(pr, types.StatusMerged, "")
+
+		if d := cfg.MergeFor(pr.Repo).DeleteBranch; d != nil && *d {
+			if err := github.DeleteHeadBranch(pr.Repo, pr.Number); err != nil {
+				display.PrintError("delete branch", pr, err)
+			}
+		}
+	}
+
+	return true
+}
+
+// queuedPlan is what a queued action comes to under the current config and
+// policy
+type queuedPlan struct {
+	approve, merge bool
+	requireChecks  bool
+	reason         string // why the action can't run now, "" when it can
+	drop           bool   // with a reason, the action is finished with
+}
+
+// planQueued revalidates a queued action against the config and policy,
+// which may have changed since it was queued
+func planQueued(cfg *config.Config, checks *policyCheck, action types.QueuedAction, pr types.PR) queuedPlan {
+	approve := action.Action == tui.QueueApprove || action.Action == tui.QueueApproveAndMerge
+	merge := action.Action == tui.QueueMerge || action.Action == tui.QueueApproveAndMerge
+	if !approve && !merge {
+		return queuedPlan{reason: fmt.Sprintf("unknown action %q", action.Action), drop: true}
+	}
+
+	if err := cfg.CheckAllowed(pr.Repo, config.ActionApprove); approve && err != nil {
+		return queuedPlan{reason: err.Error(), drop: true}
+	}
+	if err := cfg.CheckAllowed(pr.Repo, config.ActionMerge); merge && err != nil {
+		return queuedPlan{reason: err.Error(), drop: true}
+	}
+
+	rule := checks.evaluate(pr)
+	if rule.Decision == policy.Deny {
+		return queuedPlan{reason: rule.CheckApprove().Error(), drop: true}
+	}
+	if approve {
+		if err := rule.CheckApprove(); err != nil {
+			if !merge {
+				return queuedPlan{reason: err.Error(), drop: true}
+			}
+			// Leave the approval to a person and merge once they have
+			approve = false
+		}
+	}
+	if merge {
+		if err := rule.CheckMerge(approvalsOf(pr)); err != nil {
+			return queuedPlan{reason: err.Error()}
+		}
+	}
+
+	requireChecks := action.RequireChecks
+	if rule.RequireChecks != nil {
+		requireChecks = *rule.RequireChecks
+	}
+	return queuedPlan{approve: approve, merge: merge, requireChecks: requireChecks}
+}
+
+// requeueAsMerge replaces a queued approve-and-merge whose approval went
+// through with a plain merge
+func requeueAsMerge(action types.QueuedAction) {
+	err := cache.UpdateQueue(func(q []types.QueuedAction) []types.QueuedAction {
+		for i := range q {
+			if q[i].Repo == action.Repo && q[i].Number == action.Number {
+				q[i].Action = tui.QueueMerge
+			}
+		}
+		return q
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to update queue for %s#%d: %v\n", action.Repo, action.Number, err)
+	}
+}
+
+// describeQueued renders a queued action with its merge settings
+func describeQueued(action types.QueuedAction) string {
+	if action.Action == tui.QueueApprove {
+		return action.Action
+	}
+	desc := fmt.Sprintf("%s (%s", action.Action, action.MergeMethod)
+	if action.RequireChecks {
+		desc += ", checks required"
+	}
+	return desc + ")"
+}
+
+func queuedPR(action types.QueuedAction) types.PR {
+	return types.PR{
+		Number:  action.Number,
+		Title:   action.Title,
+		Repo:    action.Repo,
+		URL:     action.URL,
+		Author:  action.Author,
+		HeadSHA: action.HeadSHA,
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"github.com/jackchuka/gh-dep/internal/tui"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestPlanQueuedAuthorRule(t *testing.T) {
+	cfg := &config.Config{Policy: policy.Policy{Rules: []policy.Rule{
+		{Name: "no-snyk", Match: policy.Match{Authors: []string{"snyk-bot"}}, Decision: policy.Deny},
+	}}}
+	if err := cfg.Policy.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	checks, err := newPolicyCheck(cfg)
+	if err != nil {
+		t.Fatalf("newPolicyCheck() error = %v", err)
+	}
+
+	tests := []struct {
+		author   string
+		wantDrop bool
+	}{
+		{author: "snyk-bot", wantDrop: true},
+		{author: "dependabot[bot]", wantDrop: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.author, func(t *testing.T) {
+			action := types.QueuedAction{
+				Repo:   "org/app",
+				Number: 1,
+				Title:  "Bump lodash from 4.17.20 to 4.17.21",
+				Author: tt.author,
+				Action: tui.QueueApprove,
+			}
+
+			plan := planQueued(cfg, checks, action, queuedPR(action))
+			if plan.drop != tt.wantDrop {
+				t.Fatalf("planQueued() = %+v, want drop %v", plan, tt.wantDrop)
+			}
+			if tt.wantDrop && !strings.Contains(plan.reason, "denied by policy") {
+				t.Errorf("reason = %q, want denied by policy", plan.reason)
+			}
+			if !tt.wantDrop && !plan.approve {
+				t.Errorf("planQueued() = %+v, want an approval", plan)
+			}
+		})
+	}
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
 	"github.com/jackchuka/gh-dep/internal/github"
 	"github.com/jackchuka/gh-dep/internal/tui"
 	"github.com/spf13/cobra"
@@ -22,6 +24,23 @@ var (
 	rootReviewRequested string
 	rootArchived        bool
 	rootBot             string
+	rootByDirectory     bool
+	rootGroupBy         string
+	rootHeadPrefix      string
+	rootDiscoverLabel   string
+	rootExcludeLabel    string
+	rootExcludeRepo     string
+	rootTopic           string
+	rootByBranch        bool
+	rootFromCache       bool
+	rootScope           string
+	rootProfile         string
+	rootConcurrency     int
+	rootWait            bool
+	rootTrain           bool
+	rootAtomic          bool
+	rootTimeout         time.Duration
+	noCache             bool
 )
 
 var rootCmd = &cobra.Command{
@@ -32,7 +51,12 @@ dependency update PRs by grouping, bulk approving, and bulk merging them.
 
 When run without subcommands, launches interactive TUI mode.`,
 	SilenceUsage: true,
-	RunE:         runRoot,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if noCache {
+			github.DisableResponseCache()
+		}
+	},
+	RunE: runRoot,
 }
 
 func Execute() error {
@@ -40,25 +64,61 @@ func Execute() error {
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	cfg, err := loadConfig(rootProfile)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+
+	groupBy, err := github.ParseGroupBy(rootGroupBy)
+	if err != nil {
+		return err
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return err
+	}
+
+	groupOpts := github.GroupOptions{
+		Patterns:    cfg.GetPatterns(),
+		Bots:        registry,
+		Strategy:    groupBy,
+		ByDirectory: rootByDirectory,
+	}
+
+	mode := parseMode(rootMode)
+	mergeMethod, requireChecks := resolveMerge(cmd, "merge-method", rootMergeMethod, rootRequireCheck, cfg, "")
+
+	if rootFromCache {
+		return runRootFromCache(resolveCachedScope(rootScope, cfg), mode, mergeMethod, requireChecks, groupOpts, cfg)
 	}
 
 	owner, repos := resolveScope(cmd, rootRepo, rootOwner, cfg)
-	authors, err := resolveAuthors(cmd, rootAuthor, rootBot)
+	authors, err := resolveAuthors(cmd, rootAuthor, rootBot, cfg)
 	if err != nil {
 		return err
 	}
 
+	headPrefixes, err := resolveHeadPrefixes(rootByBranch, rootHeadPrefix, resolveBot(cmd, rootBot, cfg), cfg)
+	if err != nil {
+		return err
+	}
+
+	excludeLabels, excludeRepos := resolveExclusions(cmd, rootExcludeLabel, rootExcludeRepo, cfg)
+
 	searchParams := github.SearchParams{
 		Owner:           owner,
 		Repos:           repos,
-		Label:           rootLabel,
+		Label:           resolveLabel(cmd, rootLabel, cfg),
 		Authors:         authors,
-		Limit:           rootLimit,
+		Limit:           resolveLimit(cmd, rootLimit, cfg),
 		ReviewRequested: rootReviewRequested,
 		Archived:        rootArchived,
+		HeadPrefixes:    headPrefixes,
+		DiscoverLabels:  cleanRepos(rootDiscoverLabel),
+		ExcludeLabels:   excludeLabels,
+		ExcludeRepos:    excludeRepos,
+		Topics:          cleanRepos(rootTopic),
 	}
 
 	allPRs, err := github.SearchPRs(searchParams)
@@ -71,20 +131,41 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Parse mode
-	mode := tui.ModeApprove // default
-	switch rootMode {
-	case "approve":
-		mode = tui.ModeApprove
-	case "merge":
-		mode = tui.ModeMerge
-	case "approve-and-merge", "both":
-		mode = tui.ModeApproveAndMerge
+	// Launch TUI
+	model := tui.NewModel(allPRs, mergeMethod, requireChecks, mode, searchParams, groupOpts)
+	model.SetConfig(cfg)
+	model.SetConcurrency(rootConcurrency)
+	model.SetMergeWhenReady(rootWait, rootTimeout)
+	model.SetTrain(rootTrain)
+	model.SetAtomic(rootAtomic)
+	return runTUI(model)
+}
+
+// runRootFromCache launches the TUI on the cached groups without searching
+// GitHub, queuing actions until the list is refreshed
+func runRootFromCache(scope string, mode tui.ExecutionMode, mergeMethod string, requireChecks bool, groupOpts github.GroupOptions, cfg *config.Config) error {
+	entry, err := loadCachedEntry(scope, 0)
+	if err != nil {
+		return err
 	}
 
-	// Launch TUI
-	model := tui.NewModel(allPRs, rootMergeMethod, rootRequireCheck, mode, searchParams, cfg.GetPatterns())
+	prs := snapshotPRs(entry)
+	if len(prs) == 0 {
+		fmt.Println("No pending dependency PRs in the cached snapshot")
+		return nil
+	}
+
+	model := tui.NewModel(prs, mergeMethod, requireChecks, mode, github.ParamsFromRecord(entry.Params), groupOpts)
+	model.SetSnapshot(entry.FetchedAt)
+	model.SetConfig(cfg)
+	model.SetConcurrency(rootConcurrency)
+	model.SetMergeWhenReady(rootWait, rootTimeout)
+	model.SetTrain(rootTrain)
+	model.SetAtomic(rootAtomic)
+	return runTUI(model)
+}
 
+func runTUI(model *tui.Model) error {
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("failed to run TUI: %w", err)
@@ -93,21 +174,54 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// parseMode parses --mode, defaulting to approve
+func parseMode(value string) tui.ExecutionMode {
+	switch value {
+	case "merge":
+		return tui.ModeMerge
+	case "approve-and-merge", "both":
+		return tui.ModeApproveAndMerge
+	default:
+		return tui.ModeApprove
+	}
+}
+
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't use or update the on-disk HTTP response cache")
+	rootCmd.Flags().StringVar(&rootProfile, "profile", "", "Configured profile to use for scope, authors, label, limit, and merge settings (flags still override)")
 	rootCmd.Flags().IntVar(&rootLimit, "limit", 200, "Max PRs to fetch per repo")
 	rootCmd.Flags().StringVar(&rootLabel, "label", "", "PR label to filter")
 	rootCmd.Flags().StringVar(&rootAuthor, "author", "", "PR author to filter")
-	rootCmd.Flags().StringVar(&rootBot, "bot", "all", "Dependency bot to target: all, dependabot, or renovate (overridden by --author)")
+	rootCmd.Flags().StringVar(&rootBot, "bot", "all", "Dependency bot(s) to target: all, or comma-separated names such as dependabot, renovate, snyk, pre-commit-ci, depfu (overridden by --author)")
 	rootCmd.Flags().StringVarP(&rootRepo, "repo", "R", "", "Target repo(s), comma-separated")
 	rootCmd.Flags().StringVar(&rootOwner, "owner", "", "Target owner (user or org)")
+	rootCmd.Flags().StringVar(&rootTopic, "topic", "", "Only target repos tagged with one of these topic(s), comma-separated")
+	rootCmd.Flags().StringVar(&rootExcludeLabel, "exclude-label", "", "Skip PRs carrying any of these label(s), comma-separated (replaces the configured exclude.labels)")
+	rootCmd.Flags().StringVar(&rootExcludeRepo, "exclude-repo", "", "Skip these repo(s): OWNER/REPO, REPO, or globs, comma-separated (replaces the configured exclude.repos)")
 	rootCmd.Flags().StringVar(&rootMergeMethod, "merge-method", "squash", "Merge method: merge, squash, or rebase")
 	rootCmd.Flags().BoolVar(&rootRequireCheck, "require-checks", false, "Require CI checks to pass")
+	rootCmd.Flags().BoolVar(&rootWait, "wait", false, "Start with merge when ready on: merges wait for pending CI and go through once it passes (toggle with w)")
+	rootCmd.Flags().BoolVar(&rootTrain, "train", false, "Start with the merge train on: one PR per repo is merged at a time, each rebased first and merged once its CI passes (toggle with t)")
+	rootCmd.Flags().BoolVar(&rootAtomic, "atomic", false, "Start with atomic group merges on: the selected PRs are checked first and a group is merged only if all of its PRs can be (toggle with A)")
+	rootCmd.Flags().DurationVar(&rootTimeout, "timeout", 30*time.Minute, "How long merge when ready and the train wait for pending CI or rebases before giving up")
+	rootCmd.Flags().IntVar(&rootConcurrency, "concurrency", executor.DefaultConcurrency, "Repos to execute actions in at once; PRs of a repo run one at a time")
 	rootCmd.Flags().StringVar(&rootMode, "mode", "approve", "Execution mode: approve, merge, or approve-and-merge (both)")
 	rootCmd.Flags().StringVar(&rootReviewRequested, "review-requested", "", "Filter PRs by review requested from user or team (e.g., '@me' or 'username')")
 	rootCmd.Flags().BoolVar(&rootArchived, "archived", false, "Include PRs from archived repositories")
+	rootCmd.Flags().BoolVar(&rootByBranch, "by-branch", false, "Also discover PRs by the head branch prefix of the selected bots (e.g., renovate/), whoever opened them")
+	rootCmd.Flags().StringVar(&rootHeadPrefix, "head-prefix", "", "Also discover PRs whose head branch starts with these prefix(es), comma-separated")
+	rootCmd.Flags().StringVar(&rootDiscoverLabel, "discover-label", "", "Also discover PRs carrying these label(s), comma-separated, whoever opened them")
+	rootCmd.Flags().StringVar(&rootGroupBy, "group-by", "package-version", "Strategy for the group filter: package-version, package, package-major, ecosystem, repo, update-type, or group-name")
+	rootCmd.Flags().BoolVar(&rootByDirectory, "by-directory", false, "Make the group filter also match the directory the update applies to")
+
+	rootCmd.Flags().BoolVar(&rootFromCache, "from-cache", false, "Browse the cached groups from list --group without contacting GitHub; actions are queued for 'gh dep queue run'")
+	rootCmd.Flags().StringVar(&rootScope, "scope", "", "Cached scope to browse with --from-cache (default: the profile's scope, else the most recent list --group)")
 
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(groupsCmd)
 	rootCmd.AddCommand(approveCmd)
 	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(patternsCmd)
+	rootCmd.AddCommand(queueCmd)
+	rootCmd.AddCommand(configCmd)
 }
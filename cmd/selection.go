@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// updateTypes and ciStates list the values --update-type and --ci accept
+var (
+	updateTypes = []string{parser.UpdateMajor, parser.UpdateMinor, parser.UpdatePatch, parser.UpdateDigest, parser.UpdateUnknown}
+	ciStates    = []string{"success", "pending", "failure", "none"}
+)
+
+// groupSelection picks the cached PRs approve and merge act on: the groups
+// named by --group (keys or globs) or every group with --all, narrowed by
+// the filters
+type groupSelection struct {
+	groups      []string
+	all         bool
+	updateTypes string
+	ci          string
+	repos       string
+}
+
+func (s *groupSelection) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&s.groups, "group", nil, "Group key from list --group (e.g., lodash@4.17.21) or a glob (e.g., 'eslint*'); repeatable")
+	cmd.Flags().BoolVar(&s.all, "all", false, "Select every cached group (narrow with --update-type, --ci, and --repo)")
+	cmd.Flags().StringVar(&s.updateTypes, "update-type", "", "Only PRs with these update type(s), comma-separated: "+strings.Join(updateTypes, ", "))
+	cmd.Flags().StringVar(&s.ci, "ci", "", "Only PRs whose cached CI status is one of these, comma-separated: "+strings.Join(ciStates, ", "))
+	cmd.Flags().StringVarP(&s.repos, "repo", "R", "", "Only PRs in these repo(s): OWNER/REPO, REPO, or globs, comma-separated")
+	cmd.MarkFlagsOneRequired("group", "all")
+	cmd.MarkFlagsMutuallyExclusive("group", "all")
+}
+
+// prGroup is a selected group and its selected PRs
+type prGroup struct {
+	Key string
+	PRs []types.PR
+}
+
+// selectPRs returns the selected PRs of a cache entry, each once, in the
+// order of the groups they belong to. opts parses titles for --update-type.
+func (s *groupSelection) selectPRs(entry *types.CacheEntry, opts github.GroupOptions) ([]types.PR, error) {
+	groups, err := s.selectGroups(entry, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []types.PR
+	for _, g := range groups {
+		prs = append(prs, g.PRs...)
+	}
+	return prs, nil
+}
+
+// selectGroups returns the selected groups with their selected PRs. A PR
+// in several groups belongs to the first; groups left without PRs are
+// dropped.
+func (s *groupSelection) selectGroups(entry *types.CacheEntry, opts github.GroupOptions) ([]prGroup, error) {
+	wantTypes := cleanRepos(s.updateTypes)
+	for _, t := range wantTypes {
+		if !slices.Contains(updateTypes, t) {
+			return nil, fmt.Errorf("invalid value for --update-type: %q (expected one of %s)", t, strings.Join(updateTypes, ", "))
+		}
+	}
+	wantCI := cleanRepos(s.ci)
+	for _, state := range wantCI {
+		if !slices.Contains(ciStates, state) {
+			return nil, fmt.Errorf("invalid value for --ci: %q (expected one of %s)", state, strings.Join(ciStates, ", "))
+		}
+	}
+	repos := cleanRepos(s.repos)
+
+	keys, err := s.groupKeys(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var groups []prGroup
+	for _, key := range keys {
+		var prs []types.PR
+		for _, pr := range entry.Groups[key] {
+			id := fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
+			if seen[id] {
+				continue
+			}
+			if len(repos) > 0 && !github.MatchRepo(pr.Repo, repos) {
+				continue
+			}
+			if len(wantTypes) > 0 && !slices.Contains(wantTypes, opts.ParseUpdate(pr).UpdateType()) {
+				continue
+			}
+			if len(wantCI) > 0 && !slices.Contains(wantCI, ciState(pr)) {
+				continue
+			}
+			seen[id] = true
+			prs = append(prs, pr)
+		}
+		if len(prs) > 0 {
+			groups = append(groups, prGroup{Key: key, PRs: prs})
+		}
+	}
+	return groups, nil
+}
+
+// groupKeys resolves --group and --all to cached group keys. Exact keys
+// and globs must each match at least one group.
+func (s *groupSelection) groupKeys(entry *types.CacheEntry) ([]string, error) {
+	all := slices.Sorted(maps.Keys(entry.Groups))
+	if s.all {
+		return all, nil
+	}
+
+	var keys []string
+	for _, pattern := range s.groups {
+		if !isGlob(pattern) {
+			if _, err := findCachedGroup(entry, pattern); err != nil {
+				return nil, err
+			}
+			if !slices.Contains(keys, pattern) {
+				keys = append(keys, pattern)
+			}
+			continue
+		}
+
+		re := globRegexp(pattern)
+		matched := false
+		for _, key := range all {
+			if re.MatchString(key) {
+				matched = true
+				if !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no cached groups match '%s' (scope: %s)", pattern, entry.Scope)
+		}
+	}
+	return keys, nil
+}
+
+// ciState is a PR's cached CI status, "none" when no checks were reported
+func ciState(pr types.PR) string {
+	if pr.CIStatus == "" {
+		return "none"
+	}
+	return pr.CIStatus
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// globRegexp compiles a group glob. Unlike path.Match, * also matches the
+// slashes in scoped package names, so @types/* matches @types/node@20.1.0.
+func globRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
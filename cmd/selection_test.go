@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestSelectPRs(t *testing.T) {
+	entry := &types.CacheEntry{
+		Scope: "org",
+		Groups: map[string][]types.PR{
+			"@types/node@20.1.1": {
+				{Repo: "org/web", Number: 1, Title: "Bump @types/node from 20.1.0 to 20.1.1", CIStatus: "success"},
+				{Repo: "org/api", Number: 2, Title: "Bump @types/node from 20.1.0 to 20.1.1", CIStatus: "failure"},
+			},
+			"eslint@9.0.0": {
+				{Repo: "org/web", Number: 3, Title: "Bump eslint from 8.57.0 to 9.0.0", CIStatus: "success"},
+			},
+			"eslint-plugin-react@7.34.2": {
+				{Repo: "org/legacy-web", Number: 4, Title: "Bump eslint-plugin-react from 7.34.1 to 7.34.2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		sel  groupSelection
+		want string
+	}{
+		{name: "exact key", sel: groupSelection{groups: []string{"eslint@9.0.0"}}, want: "org/web#3"},
+		{name: "scoped glob", sel: groupSelection{groups: []string{"@types/*@*"}}, want: "org/web#1 org/api#2"},
+		{name: "repeated", sel: groupSelection{groups: []string{"eslint*", "eslint@9.0.0"}}, want: "org/legacy-web#4 org/web#3"},
+		{name: "green patches", sel: groupSelection{all: true, updateTypes: "patch", ci: "success"}, want: "org/web#1"},
+		{name: "no checks", sel: groupSelection{all: true, ci: "none"}, want: "org/legacy-web#4"},
+		{name: "repo", sel: groupSelection{all: true, repos: "org/legacy-*,api"}, want: "org/api#2 org/legacy-web#4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs, err := tt.sel.selectPRs(entry, github.GroupOptions{})
+			if err != nil {
+				t.Fatalf("selectPRs() error = %v", err)
+			}
+			var got []string
+			for _, pr := range prs {
+				got = append(got, fmt.Sprintf("%s#%d", pr.Repo, pr.Number))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("selectPRs() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPRsErrors(t *testing.T) {
+	entry := &types.CacheEntry{Scope: "org", Groups: map[string][]types.PR{"lodash@4.17.21": {{Repo: "org/app", Number: 1}}}}
+
+	tests := []struct {
+		sel  groupSelection
+		want string
+	}{
+		{groupSelection{groups: []string{"lodash@4.17.20"}}, "group 'lodash@4.17.20' not found"},
+		{groupSelection{groups: []string{"axios*"}}, "no cached groups match 'axios*'"},
+		{groupSelection{all: true, updateTypes: "huge"}, "invalid value for --update-type"},
+		{groupSelection{all: true, ci: "green"}, "invalid value for --ci"},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.sel.selectPRs(entry, github.GroupOptions{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("selectPRs(%+v) error = %v, want it to contain %q", tt.sel, err, tt.want)
+		}
+	}
+}
@@ -2,23 +2,49 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/cache"
 	"github.com/jackchuka/gh-dep/internal/config"
 	"github.com/spf13/cobra"
 )
 
-// resolveScope determines owner and repo targets, falling back to @me when nothing is provided.
+// loadConfig loads the configuration with the named profile (if any) applied
+func loadConfig(profile string) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(cfg.Ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: ignoring %s from %s: repo config files can only tighten the policy, and only set the scope and exclusions unless your user config sets trust-repo-config: true\n",
+			strings.Join(cfg.Ignored, ", "), config.RepoConfigPath())
+	}
+
+	if err := cfg.UseProfile(profile); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// resolveScope determines owner and repo targets. Flags win over the
+// configured scope, falling back to @me when nothing is provided.
 func resolveScope(cmd *cobra.Command, repoValue, ownerValue string, cfg *config.Config) (string, []string) {
 	var repos []string
+	owner := ownerValue
 
 	if cmd.Flags().Changed("repo") {
 		repos = cleanRepos(repoValue)
-	} else if cfg != nil && len(cfg.GetRepos()) > 0 {
+	} else if owner == "" && cfg != nil {
 		repos = append(repos, cfg.GetRepos()...)
+		if len(repos) == 0 {
+			owner = cfg.GetOwner()
+		}
 	}
 
-	owner := ownerValue
 	if owner == "" && len(repos) == 0 {
 		owner = "@me"
 	}
@@ -26,26 +52,140 @@ func resolveScope(cmd *cobra.Command, repoValue, ownerValue string, cfg *config.
 	return owner, repos
 }
 
+// resolveMerge picks the merge method and CI requirement for a repo:
+// explicit flags win, then the repo's config override, then the config
+// defaults, then the flag defaults
+func resolveMerge(cmd *cobra.Command, methodFlag string, method string, requireChecks bool, cfg *config.Config, repo string) (string, bool) {
+	settings := cfg.MergeFor(repo)
+
+	if !cmd.Flags().Changed(methodFlag) && settings.Method != "" {
+		method = settings.Method
+	}
+	if !cmd.Flags().Changed("require-checks") && settings.RequireChecks != nil {
+		requireChecks = *settings.RequireChecks
+	}
+
+	return method, requireChecks
+}
+
+// resolveDeleteBranch decides whether to delete a repo's head branches
+// after merging: --delete-branch when set, then the repo's config
+func resolveDeleteBranch(cmd *cobra.Command, deleteBranch bool, cfg *config.Config, repo string) bool {
+	if settings := cfg.MergeFor(repo); !cmd.Flags().Changed("delete-branch") && settings.DeleteBranch != nil {
+		return *settings.DeleteBranch
+	}
+	return deleteBranch
+}
+
+// resolveCachedScope picks the cached scope approve and merge act on: an
+// explicit --scope, else the scope of the selected profile, else the most
+// recent one
+func resolveCachedScope(scope string, cfg *config.Config) string {
+	if scope != "" || cfg == nil || cfg.Profile == nil {
+		return scope
+	}
+	if cfg.Profile.Owner == "" && len(cfg.Profile.Repos) == 0 {
+		return scope
+	}
+	return cache.ScopeKey(cfg.Owner, cfg.Repos)
+}
+
+// resolveBot picks the --bot selection: the flag when set, then the
+// profile's bot, then the flag default
+func resolveBot(cmd *cobra.Command, botValue string, cfg *config.Config) string {
+	if !cmd.Flags().Changed("bot") && cfg != nil && cfg.Profile != nil && cfg.Profile.Bot != "" {
+		return cfg.Profile.Bot
+	}
+	return botValue
+}
+
+// resolveLabel picks the label filter: the flag when set, then the profile's
+func resolveLabel(cmd *cobra.Command, labelValue string, cfg *config.Config) string {
+	if !cmd.Flags().Changed("label") && cfg != nil && cfg.Profile != nil && cfg.Profile.Label != "" {
+		return cfg.Profile.Label
+	}
+	return labelValue
+}
+
+// resolveExclusions picks the labels and repos to leave out of searches:
+// each flag when set (even to nothing), then the configured exclusions
+func resolveExclusions(cmd *cobra.Command, labelValue, repoValue string, cfg *config.Config) ([]string, []string) {
+	labels := cleanRepos(labelValue)
+	repos := cleanRepos(repoValue)
+	if cfg == nil {
+		return labels, repos
+	}
+
+	if !cmd.Flags().Changed("exclude-label") {
+		labels = cfg.Exclude.Labels
+	}
+	if !cmd.Flags().Changed("exclude-repo") {
+		repos = cfg.Exclude.Repos
+	}
+	return labels, repos
+}
+
+// resolveLimit picks the per-repo PR limit: the flag when set, then the profile's
+func resolveLimit(cmd *cobra.Command, limitValue int, cfg *config.Config) int {
+	if !cmd.Flags().Changed("limit") && cfg != nil && cfg.Profile != nil && cfg.Profile.Limit > 0 {
+		return cfg.Profile.Limit
+	}
+	return limitValue
+}
+
 // resolveAuthors picks the effective author filters based on flags.
-// --author wins; otherwise --bot is mapped to known logins.
-func resolveAuthors(cmd *cobra.Command, authorValue, botValue string) ([]string, error) {
+// --author wins, then --bot, then the profile's authors or bot; the bot
+// selection is mapped to logins through the bot registry.
+func resolveAuthors(cmd *cobra.Command, authorValue, botValue string, cfg *config.Config) ([]string, error) {
 	if cmd.Flags().Changed("author") {
 		return []string{authorValue}, nil
 	}
 
-	switch normalized := strings.TrimSpace(strings.ToLower(strings.TrimPrefix(botValue, "@"))); normalized {
-	case "all", "both", "":
-		return []string{"dependabot[bot]", "renovate[bot]"}, nil
-	case "dependabot", "dependabot[bot]":
-		return []string{"dependabot[bot]"}, nil
-	case "renovate", "renovate[bot]":
-		return []string{"renovate[bot]"}, nil
-	default:
-		return nil, fmt.Errorf("invalid value for --bot: %q (expected all, dependabot, or renovate)", botValue)
+	if !cmd.Flags().Changed("bot") && cfg != nil && cfg.Profile != nil && len(cfg.Profile.Authors) > 0 {
+		return cfg.Profile.Authors, nil
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := registry.Resolve(resolveBot(cmd, botValue, cfg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --bot: %w", err)
+	}
+
+	return bots.Logins(selected), nil
+}
+
+// resolveHeadPrefixes picks the head branch prefixes to discover PRs by:
+// explicit --head-prefix values, plus the branch prefixes of the --bot
+// selection when --by-branch is set.
+func resolveHeadPrefixes(byBranch bool, prefixValue, botValue string, cfg *config.Config) ([]string, error) {
+	prefixes := cleanRepos(prefixValue)
+	if !byBranch {
+		return prefixes, nil
+	}
+
+	registry, err := cfg.BotRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := registry.Resolve(botValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --bot: %w", err)
+	}
+
+	for _, b := range selected {
+		if b.BranchPrefix != "" && !slices.Contains(prefixes, b.BranchPrefix) {
+			prefixes = append(prefixes, b.BranchPrefix)
+		}
 	}
+	return prefixes, nil
 }
 
-// cleanRepos splits comma-separated repos, trimming blanks.
+// cleanRepos splits comma-separated repos (or other values), trimming blanks.
 func cleanRepos(repoValue string) []string {
 	var repos []string
 	for r := range strings.SplitSeq(repoValue, ",") {
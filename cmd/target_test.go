@@ -4,6 +4,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/jackchuka/gh-dep/internal/bots"
 	"github.com/jackchuka/gh-dep/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -70,7 +71,7 @@ func TestResolveScopeKeepsExplicitOwner(t *testing.T) {
 
 func TestResolveAuthorsDefaultsBothBots(t *testing.T) {
 	c := newTestCommand()
-	authors, err := resolveAuthors(c, "", "all")
+	authors, err := resolveAuthors(c, "", "all", &config.Config{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -93,7 +94,7 @@ func TestResolveAuthorsSingleBot(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := newTestCommand()
-			authors, err := resolveAuthors(c, "", tt.bot)
+			authors, err := resolveAuthors(c, "", tt.bot, &config.Config{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -109,7 +110,7 @@ func TestResolveAuthorsAuthorFlagOverrides(t *testing.T) {
 	if err := c.Flags().Set("author", "someuser"); err != nil {
 		t.Fatalf("failed to set author flag: %v", err)
 	}
-	authors, err := resolveAuthors(c, "someuser", "all")
+	authors, err := resolveAuthors(c, "someuser", "all", &config.Config{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -119,9 +120,180 @@ func TestResolveAuthorsAuthorFlagOverrides(t *testing.T) {
 	}
 }
 
+func TestResolveAuthorsConfiguredBots(t *testing.T) {
+	cfg := &config.Config{
+		Bots: []bots.Bot{
+			{Name: "renovate", Logins: []string{"acme-renovate[bot]"}},
+			{Name: "internal", Logins: []string{"acme-deps[bot]"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bot      string
+		expected []string
+	}{
+		{"all includes configured bots", "all", []string{"dependabot[bot]", "renovate[bot]", "acme-renovate[bot]", "acme-deps[bot]"}},
+		{"built-in extended with login", "renovate", []string{"renovate[bot]", "acme-renovate[bot]"}},
+		{"opt-in built-in", "snyk,depfu", []string{"snyk-bot", "depfu[bot]"}},
+		{"by login", "acme-deps[bot]", []string{"acme-deps[bot]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authors, err := resolveAuthors(newTestCommand(), "", tt.bot, cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(authors, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, authors)
+			}
+		})
+	}
+}
+
+func TestResolveAuthorsUnknownBot(t *testing.T) {
+	if _, err := resolveAuthors(newTestCommand(), "", "greenkeeper", &config.Config{}); err == nil {
+		t.Fatal("expected error for unknown bot")
+	}
+}
+
+func TestResolveHeadPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		byBranch bool
+		prefix   string
+		bot      string
+		expected []string
+	}{
+		{"none", false, "", "all", nil},
+		{"explicit only", false, "deps/, renovate/", "all", []string{"deps/", "renovate/"}},
+		{"bots' prefixes", true, "", "all", []string{"dependabot/", "renovate/"}},
+		{"single bot plus explicit", true, "renovate/", "renovate", []string{"renovate/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes, err := resolveHeadPrefixes(tt.byBranch, tt.prefix, tt.bot, &config.Config{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(prefixes, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, prefixes)
+			}
+		})
+	}
+}
+
 func newTestCommand() *cobra.Command {
 	c := &cobra.Command{}
 	c.Flags().String("repo", "", "")
 	c.Flags().String("author", "", "")
 	return c
 }
+
+func TestResolveScopeUsesConfigOwner(t *testing.T) {
+	c := newTestCommand()
+	cfg := &config.Config{Owner: "myorg"}
+
+	owner, repos := resolveScope(c, "", "", cfg)
+	if owner != "myorg" || len(repos) != 0 {
+		t.Fatalf("expected configured owner, got %q %v", owner, repos)
+	}
+
+	// An explicit owner replaces the configured repos
+	cfg = &config.Config{Repos: []string{"jackchuka/gh-dep"}}
+	owner, repos = resolveScope(c, "", "otherorg", cfg)
+	if owner != "otherorg" || len(repos) != 0 {
+		t.Fatalf("expected only the flagged owner, got %q %v", owner, repos)
+	}
+}
+
+func TestResolveMerge(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Merge: config.MergeSettings{Method: "rebase"},
+		Overrides: map[string]config.Override{
+			"myorg/legacy": {Merge: config.MergeSettings{Method: "merge", RequireChecks: &disabled}},
+		},
+	}
+
+	c := newTestCommand()
+	c.Flags().String("method", "squash", "")
+	c.Flags().Bool("require-checks", true, "")
+
+	if method, checks := resolveMerge(c, "method", "squash", true, cfg, "myorg/app"); method != "rebase" || !checks {
+		t.Errorf("myorg/app: got %s %t, want rebase true", method, checks)
+	}
+	if method, checks := resolveMerge(c, "method", "squash", true, cfg, "myorg/legacy"); method != "merge" || checks {
+		t.Errorf("myorg/legacy: got %s %t, want merge false", method, checks)
+	}
+
+	// Explicit flags win over config
+	if err := c.Flags().Set("method", "squash"); err != nil {
+		t.Fatalf("failed to set method flag: %v", err)
+	}
+	if method, _ := resolveMerge(c, "method", "squash", true, cfg, "myorg/legacy"); method != "squash" {
+		t.Errorf("flagged method: got %s, want squash", method)
+	}
+}
+
+func TestResolveProfileDefaults(t *testing.T) {
+	cfg := &config.Config{Profile: &config.Profile{Bot: "renovate", Label: "dependencies", Limit: 50}}
+
+	c := newTestCommand()
+	c.Flags().String("bot", "all", "")
+	c.Flags().String("label", "", "")
+	c.Flags().Int("limit", 200, "")
+
+	authors, err := resolveAuthors(c, "", "all", cfg)
+	if err != nil {
+		t.Fatalf("resolveAuthors failed: %v", err)
+	}
+	if !slices.Contains(authors, "renovate[bot]") || slices.Contains(authors, "dependabot[bot]") {
+		t.Errorf("authors = %v, want the profile's bot only", authors)
+	}
+	if label := resolveLabel(c, "", cfg); label != "dependencies" {
+		t.Errorf("label = %q, want the profile's", label)
+	}
+	if limit := resolveLimit(c, 200, cfg); limit != 50 {
+		t.Errorf("limit = %d, want the profile's", limit)
+	}
+
+	// Explicit flags win over the profile
+	if err := c.Flags().Set("bot", "dependabot"); err != nil {
+		t.Fatalf("failed to set bot flag: %v", err)
+	}
+	if err := c.Flags().Set("limit", "10"); err != nil {
+		t.Fatalf("failed to set limit flag: %v", err)
+	}
+	authors, err = resolveAuthors(c, "", "dependabot", cfg)
+	if err != nil {
+		t.Fatalf("resolveAuthors failed: %v", err)
+	}
+	if slices.Contains(authors, "renovate[bot]") {
+		t.Errorf("authors = %v, want the flagged bot", authors)
+	}
+	if limit := resolveLimit(c, 10, cfg); limit != 10 {
+		t.Errorf("limit = %d, want the flag's", limit)
+	}
+}
+
+func TestResolveExclusions(t *testing.T) {
+	cfg := &config.Config{Exclude: config.Exclusions{Labels: []string{"do-not-merge"}, Repos: []string{"myorg/legacy-*"}}}
+
+	c := &cobra.Command{}
+	c.Flags().String("exclude-label", "", "")
+	c.Flags().String("exclude-repo", "", "")
+
+	labels, repos := resolveExclusions(c, "", "", cfg)
+	if !slices.Equal(labels, []string{"do-not-merge"}) || !slices.Equal(repos, []string{"myorg/legacy-*"}) {
+		t.Errorf("config exclusions = %v, %v", labels, repos)
+	}
+
+	// An explicit flag replaces the configured list, even when empty
+	_ = c.Flags().Set("exclude-label", "on-hold, wip")
+	_ = c.Flags().Set("exclude-repo", "")
+	labels, repos = resolveExclusions(c, "on-hold, wip", "", cfg)
+	if !slices.Equal(labels, []string{"on-hold", "wip"}) || len(repos) != 0 {
+		t.Errorf("flag exclusions = %v, %v", labels, repos)
+	}
+}
@@ -0,0 +1,242 @@
+package bots
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Commands describes how to ask a bot to act on one of its PRs.
+// Empty values mean the bot doesn't support the action.
+type Commands struct {
+	Rebase      string // comment that asks the bot to rebase, e.g. "@dependabot rebase"
+	Recreate    string // comment that asks the bot to recreate the PR from scratch
+	RebaseLabel string // label that asks the bot to rebase, e.g. Renovate's "rebase"
+}
+
+// Bot describes a dependency update bot
+type Bot struct {
+	Name          string
+	Logins        []string // PR author logins, e.g. "dependabot[bot]"
+	TitlePatterns []string // extra title patterns tried before the built-in ones
+	BranchPrefix  string   // head branch prefix of the bot's PRs, e.g. "dependabot/"
+	Commands      Commands
+	Default       bool // targeted by --bot all
+}
+
+// builtins are the bots gh-dep knows about out of the box. Only Dependabot
+// and Renovate are targeted by default; the others are opt-in by name.
+var builtins = []Bot{
+	{
+		Name:         "dependabot",
+		Logins:       []string{"dependabot[bot]"},
+		BranchPrefix: "dependabot/",
+		Commands: Commands{
+			Rebase:   "@dependabot rebase",
+			Recreate: "@dependabot recreate",
+		},
+		Default: true,
+	},
+	{
+		Name:         "renovate",
+		Logins:       []string{"renovate[bot]"},
+		BranchPrefix: "renovate/",
+		Commands: Commands{
+			RebaseLabel: "rebase",
+		},
+		Default: true,
+	},
+	{
+		Name:   "snyk",
+		Logins: []string{"snyk-bot"},
+		TitlePatterns: []string{
+			// "[Snyk] Upgrade lodash from 4.17.20 to 4.17.21", "[Snyk] Security upgrade django from 3.2 to 3.2.19"
+			`(?i)\[snyk\]\s+(?:security\s+)?upgrade\s+(?P<package>[^\s]+)\s+from\s+(?P<from>[^\s]+)\s+to\s+(?P<to>[^\s]+)`,
+		},
+		BranchPrefix: "snyk-",
+	},
+	{
+		Name:         "pre-commit-ci",
+		Logins:       []string{"pre-commit-ci[bot]"},
+		BranchPrefix: "pre-commit-ci-update-config",
+	},
+	{
+		Name:   "depfu",
+		Logins: []string{"depfu[bot]"},
+		TitlePatterns: []string{
+			// "Update lodash 4.17.20 → 4.17.21", "Update lodash to version 4.17.21"
+			`(?i)update\s+(?P<package>[^\s]+?):?\s+(?P<from>[^\s]+)\s+(?:→|->)\s+(?P<to>[^\s]+)`,
+			`(?i)update\s+(?P<package>[^\s]+)\s+to\s+version\s+(?P<to>[^\s]+)`,
+		},
+		BranchPrefix: "depfu/",
+		Commands: Commands{
+			Rebase:   "@depfu rebase",
+			Recreate: "@depfu recreate",
+		},
+	},
+}
+
+// Registry holds the known bots: the built-in definitions plus any
+// defined in config
+type Registry struct {
+	bots []Bot
+}
+
+// NewRegistry returns a registry with the built-in bots merged with the
+// given definitions. A definition whose name matches a built-in bot extends
+// it (extra logins and patterns, overriding branch prefix and commands) and
+// enables it by default; other definitions are added as new default bots.
+func NewRegistry(defined []Bot) (*Registry, error) {
+	r := &Registry{}
+	for _, b := range builtins {
+		b.Logins = slices.Clone(b.Logins)
+		b.TitlePatterns = slices.Clone(b.TitlePatterns)
+		r.bots = append(r.bots, b)
+	}
+
+	for _, d := range defined {
+		name := normalizeName(d.Name)
+		if name == "" {
+			return nil, fmt.Errorf("bot definition is missing a name")
+		}
+
+		idx := slices.IndexFunc(r.bots, func(b Bot) bool { return b.Name == name })
+		if idx < 0 {
+			if len(d.Logins) == 0 {
+				return nil, fmt.Errorf("bot %q: at least one login is required", d.Name)
+			}
+			d.Name = name
+			d.Default = true
+			r.bots = append(r.bots, d)
+			continue
+		}
+
+		b := &r.bots[idx]
+		for _, login := range d.Logins {
+			if !slices.Contains(b.Logins, login) {
+				b.Logins = append(b.Logins, login)
+			}
+		}
+		b.TitlePatterns = append(b.TitlePatterns, d.TitlePatterns...)
+		if d.BranchPrefix != "" {
+			b.BranchPrefix = d.BranchPrefix
+		}
+		if d.Commands != (Commands{}) {
+			b.Commands = d.Commands
+		}
+		b.Default = true
+	}
+
+	return r, nil
+}
+
+// Builtin returns a registry with only the built-in bots
+func Builtin() *Registry {
+	r, _ := NewRegistry(nil)
+	return r
+}
+
+// All returns every registered bot
+func (r *Registry) All() []Bot {
+	return r.bots
+}
+
+// Names returns the names of every registered bot
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.bots))
+	for i, b := range r.bots {
+		names[i] = b.Name
+	}
+	return names
+}
+
+// Get looks up a bot by name or by one of its logins
+func (r *Registry) Get(value string) (Bot, bool) {
+	name := normalizeName(value)
+	for _, b := range r.bots {
+		if b.Name == name || slices.Contains(b.Logins, strings.TrimPrefix(strings.TrimSpace(value), "@")) {
+			return b, true
+		}
+	}
+	return Bot{}, false
+}
+
+// ForLogin returns the bot that authors PRs as the given login
+func (r *Registry) ForLogin(login string) (Bot, bool) {
+	for _, b := range r.bots {
+		if slices.Contains(b.Logins, login) {
+			return b, true
+		}
+	}
+	return Bot{}, false
+}
+
+// ForPR returns the bot behind a PR: the one that authors PRs as author,
+// else the one whose branch prefix headRef starts with, for bots running
+// under a service account
+func (r *Registry) ForPR(author, headRef string) (Bot, bool) {
+	if b, ok := r.ForLogin(author); ok {
+		return b, true
+	}
+	if headRef == "" {
+		return Bot{}, false
+	}
+	for _, b := range r.bots {
+		if b.BranchPrefix != "" && strings.HasPrefix(headRef, b.BranchPrefix) {
+			return b, true
+		}
+	}
+	return Bot{}, false
+}
+
+// Resolve turns a --bot value into bots. "all" (or empty) selects the
+// default bots; otherwise the value is a comma-separated list of names or
+// logins.
+func (r *Registry) Resolve(value string) ([]Bot, error) {
+	normalized := normalizeName(value)
+	if normalized == "all" || normalized == "both" || normalized == "" {
+		var selected []Bot
+		for _, b := range r.bots {
+			if b.Default {
+				selected = append(selected, b)
+			}
+		}
+		return selected, nil
+	}
+
+	var selected []Bot
+	for part := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		b, ok := r.Get(part)
+		if !ok {
+			return nil, fmt.Errorf("unknown bot: %q (expected all or one of %s)", strings.TrimSpace(part), strings.Join(r.Names(), ", "))
+		}
+		if !slices.ContainsFunc(selected, func(s Bot) bool { return s.Name == b.Name }) {
+			selected = append(selected, b)
+		}
+	}
+	return selected, nil
+}
+
+// Logins returns the logins of the given bots, in order and without duplicates
+func Logins(selected []Bot) []string {
+	var logins []string
+	for _, b := range selected {
+		for _, login := range b.Logins {
+			if !slices.Contains(logins, login) {
+				logins = append(logins, login)
+			}
+		}
+	}
+	return logins
+}
+
+// normalizeName lowercases a bot name and strips "@" and "[bot]" decorations
+// so "@Dependabot" and "dependabot[bot]" both name the dependabot bot
+func normalizeName(value string) string {
+	name := strings.ToLower(strings.TrimSpace(value))
+	name = strings.TrimPrefix(name, "@")
+	return strings.TrimSuffix(name, "[bot]")
+}
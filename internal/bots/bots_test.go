@@ -0,0 +1,111 @@
+package bots
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/parser"
+)
+
+func TestBuiltinTitlePatternsAreValid(t *testing.T) {
+	for _, b := range Builtin().All() {
+		for _, pattern := range b.TitlePatterns {
+			if err := parser.ValidatePattern(pattern); err != nil {
+				t.Errorf("bot %q: %v", b.Name, err)
+			}
+		}
+	}
+}
+
+func TestBuiltinTitlePatterns(t *testing.T) {
+	tests := []struct {
+		bot     string
+		title   string
+		wantKey string
+	}{
+		{"snyk", "[Snyk] Upgrade lodash from 4.17.20 to 4.17.21", "lodash@4.17.21"},
+		{"snyk", "[Snyk] Security upgrade django from 3.2 to 3.2.19", "django@3.2.19"},
+		{"depfu", "Update lodash 4.17.20 → 4.17.21", "lodash@4.17.21"},
+		{"depfu", "Update rails to version 7.1.3", "rails@7.1.3"},
+	}
+
+	registry := Builtin()
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			b, ok := registry.Get(tt.bot)
+			if !ok {
+				t.Fatalf("bot %q not registered", tt.bot)
+			}
+			got := parser.ParseTitle(tt.title, b.TitlePatterns)
+			if got.GroupKey() != tt.wantKey {
+				t.Errorf("GroupKey() = %q, want %q", got.GroupKey(), tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	registry, err := NewRegistry([]Bot{
+		{Name: "Renovate", Logins: []string{"acme-renovate[bot]"}},
+		{Name: "snyk"},
+		{Name: "internal", Logins: []string{"acme-deps[bot]"}, BranchPrefix: "deps/"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	renovate, ok := registry.ForLogin("acme-renovate[bot]")
+	if !ok || renovate.Name != "renovate" {
+		t.Fatalf("expected custom login to map to renovate, got %+v", renovate)
+	}
+	if !slices.Equal(renovate.Logins, []string{"renovate[bot]", "acme-renovate[bot]"}) {
+		t.Errorf("unexpected renovate logins: %v", renovate.Logins)
+	}
+
+	selected, err := registry.Resolve("all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, b := range selected {
+		names = append(names, b.Name)
+	}
+	if !slices.Equal(names, []string{"dependabot", "renovate", "snyk", "internal"}) {
+		t.Errorf("unexpected default bots: %v", names)
+	}
+
+	// Built-in definitions stay untouched
+	if b, _ := Builtin().Get("renovate"); len(b.Logins) != 1 {
+		t.Errorf("expected built-in renovate to be unchanged, got %v", b.Logins)
+	}
+
+	if _, err := NewRegistry([]Bot{{Name: "nologin"}}); err == nil {
+		t.Error("expected error for new bot without logins")
+	}
+}
+
+func TestForPR(t *testing.T) {
+	registry := Builtin()
+
+	tests := []struct {
+		name    string
+		author  string
+		headRef string
+		want    string
+	}{
+		{"by login", "dependabot[bot]", "", "dependabot"},
+		{"login wins over branch", "renovate[bot]", "dependabot/npm_and_yarn/axios-1.7.3", "renovate"},
+		{"service account by branch", "acme-ci", "renovate/lodash-4.x", "renovate"},
+		{"unknown", "octocat", "feature/renovate", ""},
+		{"no branch", "octocat", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, ok := registry.ForPR(tt.author, tt.headRef)
+			if ok != (tt.want != "") || b.Name != tt.want {
+				t.Errorf("ForPR(%q, %q) = %q, %v; want %q", tt.author, tt.headRef, b.Name, ok, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,23 @@
 package cache
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/jackchuka/gh-dep/internal/types"
 )
 
-// GetCachePath returns the path to the cache file
-func GetCachePath() (string, error) {
+// SchemaVersion is the version of the cache file format written by this
+// build. Version 1 held a single set of groups; version 2 keys entries by
+// search scope.
+const SchemaVersion = 2
+
+// Dir returns the gh-dep cache directory, creating it if needed
+func Dir() (string, error) {
 	cacheDir := os.Getenv("XDG_CACHE_HOME")
 	if cacheDir == "" {
 		home, err := os.UserHomeDir()
@@ -24,43 +32,194 @@ func GetCachePath() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(depCache, "groups.json"), nil
+	return depCache, nil
 }
 
-// Save writes the cache to disk
-func Save(cache *types.Cache) error {
-	path, err := GetCachePath()
+// GetCachePath returns the path to the cache file
+func GetCachePath() (string, error) {
+	dir, err := Dir()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	data, err := json.MarshalIndent(cache, "", "  ")
-	if err != nil {
-		return err
+	return filepath.Join(dir, "groups.json"), nil
+}
+
+// ScopeAll is the scope of searches not limited to an owner or repos
+const ScopeAll = "all"
+
+// ScopeKey returns the cache key for a search scope, e.g. "owner=myorg" or
+// "repo=owner/api,owner/app". Repos are sorted so the order of --repo values
+// doesn't matter.
+func ScopeKey(owner string, repos []string) string {
+	if owner == "" && len(repos) == 0 {
+		return ScopeAll
+	}
+
+	var parts []string
+	if owner != "" {
+		parts = append(parts, "owner="+owner)
+	}
+	if len(repos) > 0 {
+		sorted := slices.Clone(repos)
+		sort.Strings(sorted)
+		parts = append(parts, "repo="+strings.Join(sorted, ","))
 	}
+	return strings.Join(parts, ";")
+}
+
+// Save stores the entry under its scope, keeping the entries of other
+// scopes, and marks it as the most recent one
+func Save(entry *types.CacheEntry) error {
+	return update(func(c *types.Cache) bool {
+		// Carry recorded states over to a refreshed entry so approvals
+		// survive a new list --group
+		if previous, ok := c.Entries[entry.Scope]; ok {
+			carryStates(previous, entry)
+		}
 
-	return os.WriteFile(path, data, 0644)
+		c.Entries[entry.Scope] = entry
+		c.Last = entry.Scope
+		return true
+	})
 }
 
-// Load reads the cache from disk
+// Load reads the cache from disk. Returns nil if there is no cache yet.
 func Load() (*types.Cache, error) {
 	path, err := GetCachePath()
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(path)
+	unlock, err := lock(path, false)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, err
 	}
+	defer unlock()
+
+	return read(path, false)
+}
+
+// LoadEntry returns the cached entry for a scope, or the most recent entry
+// when scope is empty. Returns nil if there is no such entry.
+func LoadEntry(scope string) (*types.CacheEntry, error) {
+	c, err := Load()
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	if scope == "" {
+		scope = c.Last
+	}
+	if entry, ok := c.Entries[scope]; ok {
+		return entry, nil
+	}
+
+	// Accept a bare owner or repo list for convenience, e.g. "myorg"
+	for _, key := range []string{"owner=" + scope, "repo=" + scope} {
+		if entry, ok := c.Entries[key]; ok {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
 
-	var cache types.Cache
-	if err := json.Unmarshal(data, &cache); err != nil {
+// Entries returns every cached entry, most recently fetched first
+func Entries() ([]*types.CacheEntry, error) {
+	c, err := Load()
+	if err != nil || c == nil {
 		return nil, err
 	}
 
-	return &cache, nil
+	entries := make([]*types.CacheEntry, 0, len(c.Entries))
+	for _, entry := range c.Entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FetchedAt.After(entries[j].FetchedAt)
+	})
+	return entries, nil
+}
+
+// RecordState records the outcome of an action on a PR in every cached
+// group that contains it. An earlier approval is kept when a later action
+// fails.
+func RecordState(repo string, number int, state types.PRState) error {
+	if state.Status == types.StatusApproved {
+		state.Approved = true
+	}
+
+	return update(func(c *types.Cache) bool {
+		found := false
+		for _, entry := range c.Entries {
+			for _, prs := range entry.Groups {
+				for i := range prs {
+					if prs[i].Repo != repo || prs[i].Number != number {
+						continue
+					}
+					next := state
+					if prs[i].State.IsApproved() {
+						next.Approved = true
+					}
+					prs[i].State = &next
+					found = true
+				}
+			}
+		}
+		return found
+	})
+}
+
+func carryStates(from, to *types.CacheEntry) {
+	states := make(map[string]*types.PRState)
+	for _, prs := range from.Groups {
+		for _, pr := range prs {
+			if pr.State != nil {
+				states[fmt.Sprintf("%s#%d", pr.Repo, pr.Number)] = pr.State
+			}
+		}
+	}
+	if len(states) == 0 {
+		return
+	}
+
+	for _, prs := range to.Groups {
+		for i := range prs {
+			if prs[i].State == nil {
+				prs[i].State = states[fmt.Sprintf("%s#%d", prs[i].Repo, prs[i].Number)]
+			}
+		}
+	}
+}
+
+// update applies fn to the cache while holding an exclusive lock, so
+// concurrent gh-dep processes don't lose each other's changes. The cache is
+// written back only if fn reports a change.
+func update(fn func(c *types.Cache) bool) error {
+	path, err := GetCachePath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := read(path, true)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		c = &types.Cache{}
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]*types.CacheEntry)
+	}
+
+	if !fn(c) {
+		return nil
+	}
+	return write(path, c)
 }
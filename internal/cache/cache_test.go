@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestScopeKey(t *testing.T) {
+	tests := []struct {
+		owner string
+		repos []string
+		want  string
+	}{
+		{"myorg", nil, "owner=myorg"},
+		{"", []string{"org/web", "org/api"}, "repo=org/api,org/web"},
+		{"", nil, ScopeAll},
+	}
+
+	for _, tt := range tests {
+		if got := ScopeKey(tt.owner, tt.repos); got != tt.want {
+			t.Errorf("ScopeKey(%q, %v) = %q, want %q", tt.owner, tt.repos, got, tt.want)
+		}
+	}
+}
+
+func TestSaveKeepsOtherScopes(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	org := &types.CacheEntry{
+		Scope:     "owner=myorg",
+		FetchedAt: time.Now().Add(-time.Hour),
+		Groups:    map[string][]types.PR{"lodash@4.17.21": {{Repo: "myorg/app", Number: 1}}},
+	}
+	repo := &types.CacheEntry{
+		Scope:     "repo=myorg/api",
+		FetchedAt: time.Now(),
+		Groups:    map[string][]types.PR{"axios@1.7.3": {{Repo: "myorg/api", Number: 2}}},
+	}
+	for _, entry := range []*types.CacheEntry{org, repo} {
+		if err := Save(entry); err != nil {
+			t.Fatalf("Save(%s) failed: %v", entry.Scope, err)
+		}
+	}
+
+	last, err := LoadEntry("")
+	if err != nil || last == nil || last.Scope != repo.Scope {
+		t.Fatalf("LoadEntry(\"\") = %v, %v; want the most recent scope %q", last, err, repo.Scope)
+	}
+
+	got, err := LoadEntry("myorg")
+	if err != nil || got == nil {
+		t.Fatalf("LoadEntry(\"myorg\") = %v, %v; want the org entry", got, err)
+	}
+	if _, ok := got.Groups["lodash@4.17.21"]; !ok {
+		t.Errorf("org groups were overwritten: %v", got.Groups)
+	}
+
+	entries, err := Entries()
+	if err != nil || len(entries) != 2 || entries[0].Scope != repo.Scope {
+		t.Fatalf("Entries() = %v, %v; want 2 entries, newest first", entries, err)
+	}
+
+	missing, err := LoadEntry("other")
+	if err != nil || missing != nil {
+		t.Fatalf("LoadEntry(\"other\") = %v, %v; want nil", missing, err)
+	}
+}
+
+func TestRecordState(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	pr := types.PR{Repo: "myorg/app", Number: 1}
+	for _, scope := range []string{"owner=myorg", "repo=myorg/app"} {
+		err := Save(&types.CacheEntry{Scope: scope, Groups: map[string][]types.PR{"lodash@4.17.21": {pr}}})
+		if err != nil {
+			t.Fatalf("Save(%s) failed: %v", scope, err)
+		}
+	}
+
+	if err := RecordState(pr.Repo, pr.Number, types.PRState{Status: types.StatusApproved}); err != nil {
+		t.Fatalf("RecordState(approved) failed: %v", err)
+	}
+	if err := RecordState(pr.Repo, pr.Number, types.PRState{Status: types.StatusFailed, Reason: "merge: not mergeable"}); err != nil {
+		t.Fatalf("RecordState(failed) failed: %v", err)
+	}
+
+	for _, scope := range []string{"owner=myorg", "repo=myorg/app"} {
+		entry, err := LoadEntry(scope)
+		if err != nil || entry == nil {
+			t.Fatalf("LoadEntry(%s) = %v, %v", scope, entry, err)
+		}
+		state := entry.Groups["lodash@4.17.21"][0].State
+		if state == nil || state.Status != types.StatusFailed || !state.IsApproved() || state.Done() {
+			t.Errorf("%s: state = %+v, want failed but still approved", scope, state)
+		}
+	}
+
+	// A refreshed list keeps the recorded state
+	err := Save(&types.CacheEntry{Scope: "owner=myorg", Groups: map[string][]types.PR{"lodash@4.17.21": {pr}}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	entry, _ := LoadEntry("owner=myorg")
+	if state := entry.Groups["lodash@4.17.21"][0].State; state == nil || !state.IsApproved() {
+		t.Errorf("state after refresh = %+v, want it carried over", state)
+	}
+}
+
+func TestLoadMigratesLegacyCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path, err := GetCachePath()
+	if err != nil {
+		t.Fatalf("GetCachePath failed: %v", err)
+	}
+
+	legacy := `{"groups": {"lodash@4.17.21": [{"number": 1, "repo": "myorg/app"}]}, "group_by": "package-version"}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+
+	entry, err := LoadEntry("")
+	if err != nil || entry == nil {
+		t.Fatalf("LoadEntry(\"\") = %v, %v; want the migrated entry", entry, err)
+	}
+	if entry.Scope != legacyScope || len(entry.Groups["lodash@4.17.21"]) != 1 {
+		t.Errorf("migrated entry = %+v", entry)
+	}
+
+	// The next write stores the current schema
+	if err := Save(&types.CacheEntry{Scope: "owner=myorg"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	c, err := Load()
+	if err != nil || c.Version != SchemaVersion || len(c.Entries) != 2 {
+		t.Fatalf("Load() = %+v, %v; want version %d with both entries", c, err, SchemaVersion)
+	}
+}
+
+func TestLoadRecoversFromCorruptCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path, err := GetCachePath()
+	if err != nil {
+		t.Fatalf("GetCachePath failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"entries": {"owner=myorg": {`), 0644); err != nil {
+		t.Fatalf("failed to write corrupt cache: %v", err)
+	}
+
+	c, err := Load()
+	if err != nil || c != nil {
+		t.Fatalf("Load() = %v, %v; want an empty cache", c, err)
+	}
+	// Readers only hold a shared lock, so they leave the file in place
+	if _, err := os.Stat(path + ".corrupt"); !os.IsNotExist(err) {
+		t.Errorf("Load() moved the corrupt file aside: %v", err)
+	}
+
+	if err := Save(&types.CacheEntry{Scope: "owner=myorg"}); err != nil {
+		t.Fatalf("Save after recovery failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("expected the corrupt file to be kept aside: %v", err)
+	}
+}
+
+func TestNewerCacheIsLeftAlone(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path, err := GetCachePath()
+	if err != nil {
+		t.Fatalf("GetCachePath failed: %v", err)
+	}
+
+	newer := []byte(`{"version": 99, "entries": {"owner=myorg": {"scope": "owner=myorg"}}}`)
+	if err := os.WriteFile(path, newer, 0644); err != nil {
+		t.Fatalf("failed to write newer cache: %v", err)
+	}
+
+	var schemaErr *NewerSchemaError
+	if _, err := Load(); !errors.As(err, &schemaErr) || schemaErr.Version != 99 {
+		t.Errorf("Load() error = %v, want a NewerSchemaError for version 99", err)
+	}
+	if err := Save(&types.CacheEntry{Scope: "owner=other"}); !errors.As(err, &schemaErr) {
+		t.Errorf("Save() error = %v, want a NewerSchemaError", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != string(newer) {
+		t.Errorf("cache file = %q, %v; want it unchanged", data, err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); !os.IsNotExist(err) {
+		t.Errorf("newer cache was moved aside: %v", err)
+	}
+}
+
+func TestConcurrentRecordState(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var prs []types.PR
+	for i := 1; i <= 20; i++ {
+		prs = append(prs, types.PR{Repo: "myorg/app", Number: i})
+	}
+	if err := Save(&types.CacheEntry{Scope: "owner=myorg", Groups: map[string][]types.PR{"lodash@4.17.21": prs}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, pr := range prs {
+		wg.Go(func() {
+			if err := RecordState(pr.Repo, pr.Number, types.PRState{Status: types.StatusMerged}); err != nil {
+				t.Errorf("RecordState(#%d) failed: %v", pr.Number, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	entry, err := LoadEntry("owner=myorg")
+	if err != nil || entry == nil {
+		t.Fatalf("LoadEntry failed: %v", err)
+	}
+	for _, pr := range entry.Groups["lodash@4.17.21"] {
+		if !pr.State.Done() {
+			t.Errorf("#%d lost its state: %+v", pr.Number, pr.State)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	err := Enqueue([]types.QueuedAction{
+		{Repo: "myorg/app", Number: 1, Action: "approve"},
+		{Repo: "myorg/api", Number: 2, Action: "merge"},
+	})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	// Queuing a PR again replaces its earlier action
+	if err := Enqueue([]types.QueuedAction{{Repo: "myorg/app", Number: 1, Action: "approve-and-merge"}}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	queued, err := LoadQueue()
+	if err != nil || len(queued) != 2 {
+		t.Fatalf("LoadQueue() = %v, %v; want 2 actions", queued, err)
+	}
+	if queued[1].Repo != "myorg/app" || queued[1].Action != "approve-and-merge" {
+		t.Errorf("expected the requeued action last, got %+v", queued)
+	}
+
+	err = UpdateQueue(func(q []types.QueuedAction) []types.QueuedAction {
+		return RemoveQueued(q, "myorg/api", 2)
+	})
+	if err != nil {
+		t.Fatalf("UpdateQueue failed: %v", err)
+	}
+	if queued, _ := LoadQueue(); len(queued) != 1 || queued[0].Number != 1 {
+		t.Errorf("after removal = %+v, want only #1", queued)
+	}
+
+	if err := ClearQueue(); err != nil {
+		t.Fatalf("ClearQueue failed: %v", err)
+	}
+	if queued, _ := LoadQueue(); len(queued) != 0 {
+		t.Errorf("after clear = %+v, want empty", queued)
+	}
+}
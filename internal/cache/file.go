@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// legacyScope is the scope given to groups migrated from a version 1 cache,
+// which didn't record the search that produced them
+const legacyScope = "legacy"
+
+// legacyCache is the version 1 cache format: the groups of the last
+// list --group
+type legacyCache struct {
+	Groups      map[string][]types.PR `json:"groups"`
+	GroupBy     string                `json:"group_by,omitempty"`
+	ByDirectory bool                  `json:"by_directory,omitempty"`
+}
+
+// NewerSchemaError is returned for a cache file written by a newer gh-dep.
+// The file is left untouched so the newer build can still use it.
+type NewerSchemaError struct {
+	Path    string
+	Version int
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("cache file %s has schema version %d, newer than version %d supported by this gh-dep; upgrade gh-dep to use it", e.Path, e.Version, SchemaVersion)
+}
+
+// read loads the cache file at path, migrating older formats. A corrupt
+// file is treated as an empty cache so one bad write doesn't break every
+// command; with exclusive set, the caller holds the exclusive lock and the
+// file is also moved aside.
+func read(path string, exclusive bool) (*types.Cache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	c, err := decode(data)
+	var newer *NewerSchemaError
+	if errors.As(err, &newer) {
+		newer.Path = path
+		return nil, newer
+	}
+	if err != nil {
+		if !exclusive {
+			fmt.Fprintf(os.Stderr, "warning: ignoring corrupt cache file %s (%v)\n", path, err)
+			return nil, nil
+		}
+
+		backup := path + ".corrupt"
+		if renameErr := os.Rename(path, backup); renameErr != nil && !os.IsNotExist(renameErr) {
+			return nil, fmt.Errorf("cache file %s is corrupt (%v) and could not be moved aside: %w", path, err, renameErr)
+		}
+		fmt.Fprintf(os.Stderr, "warning: cache file was corrupt (%v); moved it to %s and started a new cache\n", err, backup)
+		return nil, nil
+	}
+
+	return c, nil
+}
+
+// decode parses cache data of any known schema version
+func decode(data []byte) (*types.Cache, error) {
+	var c types.Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	if c.Version > SchemaVersion {
+		return nil, &NewerSchemaError{Version: c.Version}
+	}
+
+	// Files without a version predate versioning: either the version 1
+	// single-group format or a version 2 file
+	if c.Version == 0 && c.Entries == nil {
+		var legacy legacyCache
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, err
+		}
+		if legacy.Groups != nil {
+			c.Entries = map[string]*types.CacheEntry{
+				legacyScope: {
+					Scope:       legacyScope,
+					Groups:      legacy.Groups,
+					GroupBy:     legacy.GroupBy,
+					ByDirectory: legacy.ByDirectory,
+				},
+			}
+			c.Last = legacyScope
+		}
+	}
+
+	c.Version = SchemaVersion
+	return &c, nil
+}
+
+// write stores the cache at path, stamped with the current schema version
+func write(path string, c *types.Cache) error {
+	c.Version = SchemaVersion
+	return writeJSON(path, c)
+}
+
+// writeJSON replaces the file at path atomically: the data goes to a
+// temporary file in the same directory which is then renamed over the old
+// one, so readers never see a partial write
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+)
+
+// lock takes an advisory lock on the cache file at path, shared for reads
+// and exclusive for writes, and returns a function that releases it. The
+// lock is held on a separate .lock file because the cache file itself is
+// replaced on every write.
+func lock(path string, exclusive bool) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache lock: %w", err)
+	}
+
+	if err := lockFile(f, exclusive); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock cache: %w", err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
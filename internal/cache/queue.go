@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// queueFile holds the actions queued while offline
+type queueFile struct {
+	Version int                  `json:"version"`
+	Actions []types.QueuedAction `json:"actions"`
+}
+
+// GetQueuePath returns the path to the queued actions file
+func GetQueuePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "queue.json"), nil
+}
+
+// LoadQueue returns the queued actions, oldest first
+func LoadQueue() ([]types.QueuedAction, error) {
+	path, err := GetQueuePath()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lock(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return readQueue(path)
+}
+
+// Enqueue adds actions to the queue. An action on a PR that is already
+// queued replaces the earlier one.
+func Enqueue(actions []types.QueuedAction) error {
+	return UpdateQueue(func(queued []types.QueuedAction) []types.QueuedAction {
+		for _, action := range actions {
+			queued = RemoveQueued(queued, action.Repo, action.Number)
+			queued = append(queued, action)
+		}
+		return queued
+	})
+}
+
+// UpdateQueue replaces the queue with what fn returns, under an exclusive
+// lock so concurrent gh-dep processes don't lose each other's changes
+func UpdateQueue(fn func(queued []types.QueuedAction) []types.QueuedAction) error {
+	path, err := GetQueuePath()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lock(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	queued, err := readQueue(path)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(path, queueFile{Version: SchemaVersion, Actions: fn(queued)})
+}
+
+// ClearQueue removes every queued action
+func ClearQueue() error {
+	return UpdateQueue(func([]types.QueuedAction) []types.QueuedAction { return nil })
+}
+
+// RemoveQueued returns the queue without the action on the given PR
+func RemoveQueued(queued []types.QueuedAction, repo string, number int) []types.QueuedAction {
+	kept := queued[:0:0]
+	for _, action := range queued {
+		if action.Repo != repo || action.Number != number {
+			kept = append(kept, action)
+		}
+	}
+	return kept
+}
+
+func readQueue(path string) ([]types.QueuedAction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var q queueFile
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("queue file %s is corrupt: %w", path, err)
+	}
+	return q.Actions, nil
+}
@@ -1,48 +1,454 @@
 package config
 
 import (
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/policy"
 )
 
-// Config holds all configuration values from gh config
+// Config holds the effective configuration, merged from (lowest precedence
+// first) the gh config dep.* keys, the user config file and the repo
+// config file. Unless the user trusts repo config files, the repo file may
+// only change the scope and exclusions and tighten the policy.
 type Config struct {
-	Repos    []string // dep.repo (comma-separated)
-	Patterns []string // dep.patterns (comma-separated regex patterns)
+	Owner     string              // default owner to target when no repos are set
+	Repos     []string            // default repos to target
+	Patterns  []string            // custom title patterns
+	Bots      []bots.Bot          // bot definitions extending the built-in ones
+	Merge     MergeSettings       // merge defaults
+	Exclude   Exclusions          // PRs to leave out of searches
+	Overrides map[string]Override // per-repo settings, keyed by OWNER/REPO or a glob such as myorg/legacy-*
+	Profiles  map[string]Profile  // named profiles, selected with --profile
+	Policy    policy.Policy       // rules deciding which PRs may be approved and merged
+
+	// TrustRepoConfig lets the repo config file change every setting; only
+	// the user config file can set it
+	TrustRepoConfig bool
+
+	// Ignored lists the settings of the repo config file that were left
+	// out because it isn't trusted or they would loosen the policy
+	Ignored []string
+
+	// Profile is the profile selected with UseProfile, or nil
+	Profile *Profile
+
+	// Sources records where each setting came from, keyed by setting name
+	// (e.g. "patterns" or "merge.method")
+	Sources map[string]string
+}
+
+// MergeSettings are merge defaults; empty values leave the built-in or
+// flag default in place
+type MergeSettings struct {
+	Method        string `yaml:"method"`         // merge, squash, or rebase
+	RequireChecks *bool  `yaml:"require-checks"` // require CI checks to pass
+	DeleteBranch  *bool  `yaml:"delete-branch"`  // delete the head branch after merging
+}
+
+// Exclusions leave PRs out of searches unless --exclude-label or
+// --exclude-repo replace them
+type Exclusions struct {
+	Labels []string `yaml:"labels"` // e.g. do-not-merge
+	Repos  []string `yaml:"repos"`  // OWNER/REPO, REPO, or globs such as myorg/legacy-*
+}
+
+// Override holds the settings for the repos matching its key
+type Override struct {
+	Merge MergeSettings `yaml:"merge"`
+	Modes []string      `yaml:"modes"` // actions gh-dep may take: approve, merge (default: both)
 }
 
-// Load reads configuration from gh config
-// Returns a Config with zero values if no config is set
+// MergeMethods lists the valid merge methods
+var MergeMethods = []string{"merge", "squash", "rebase"}
+
+// Actions that overrides can allow
+const (
+	ActionApprove = "approve"
+	ActionMerge   = "merge"
+)
+
+// Load reads the configuration and validates it
+// Returns a Config with zero values if nothing is configured, or an error
+// if a setting is invalid
 func Load() (*Config, error) {
-	ghCfg, err := config.Read(nil)
+	cfg, err := Read()
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &Config{}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
-	if repos, err := ghCfg.Get([]string{"dep.repo"}); err == nil && repos != "" {
-		parts := strings.SplitSeq(repos, ",")
-		for part := range parts {
-			part = strings.TrimSpace(part)
-			if part != "" {
-				cfg.Repos = append(cfg.Repos, part)
+	return cfg, nil
+}
+
+// Read reads the configuration without validating its values. Config files
+// with unknown keys or malformed YAML are still reported.
+func Read() (*Config, error) {
+	cfg := &Config{Sources: make(map[string]string)}
+
+	if err := cfg.readGhConfig(); err != nil {
+		return nil, err
+	}
+
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if userPath != "" {
+		fc, err := readFile(userPath)
+		if err != nil {
+			return nil, err
+		}
+		if fc != nil {
+			cfg.apply(fc, userPath)
+			if fc.TrustRepoConfig != nil {
+				cfg.TrustRepoConfig = *fc.TrustRepoConfig
+				cfg.Sources["trust-repo-config"] = userPath
 			}
 		}
 	}
 
+	// The repo file comes with the checkout, which may not be the user's
+	if repoPath := RepoConfigPath(); repoPath != "" {
+		fc, err := readFile(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if fc != nil {
+			cfg.applyRepo(fc, repoPath)
+		}
+	}
+
+	return cfg, nil
+}
+
+// readGhConfig reads the dep.* keys from gh config, the lowest precedence layer
+func (c *Config) readGhConfig() error {
+	ghCfg, err := config.Read(nil)
+	if err != nil {
+		return err
+	}
+
+	if repos, err := ghCfg.Get([]string{"dep.repo"}); err == nil && repos != "" {
+		c.Repos = splitList(repos)
+		c.Sources["repos"] = "gh config dep.repo"
+	}
+
 	if patterns, err := ghCfg.Get([]string{"dep.patterns"}); err == nil && patterns != "" {
-		parts := strings.SplitSeq(patterns, ",")
-		for part := range parts {
-			part = strings.TrimSpace(part)
-			if part != "" {
-				cfg.Patterns = append(cfg.Patterns, part)
+		c.Patterns = splitList(patterns)
+		c.Sources["patterns"] = "gh config dep.patterns"
+	}
+
+	if botsValue, err := ghCfg.Get([]string{"dep.bots"}); err == nil && botsValue != "" {
+		for _, entry := range splitList(botsValue) {
+			c.Bots = append(c.Bots, parseBotEntry(entry))
+		}
+		c.Sources["bots"] = "gh config dep.bots"
+	}
+
+	return nil
+}
+
+// apply layers the settings of a config file over the current ones
+func (c *Config) apply(fc *fileConfig, source string) {
+	// Owner and repos together define the scope, so either replaces both
+	if fc.Owner != "" || len(fc.Repos) > 0 {
+		c.Owner = fc.Owner
+		c.Repos = fc.Repos
+		c.Sources["owner"] = source
+		c.Sources["repos"] = source
+	}
+
+	if len(fc.Patterns) > 0 {
+		c.Patterns = fc.Patterns
+		c.Sources["patterns"] = source
+	}
+
+	// Bot definitions accumulate; the registry merges them by name
+	if len(fc.Bots) > 0 {
+		for _, b := range fc.Bots {
+			c.Bots = append(c.Bots, b.bot())
+		}
+		c.Sources["bots"] = source
+	}
+
+	if fc.Merge.Method != "" {
+		c.Merge.Method = fc.Merge.Method
+		c.Sources["merge.method"] = source
+	}
+	if fc.Merge.RequireChecks != nil {
+		c.Merge.RequireChecks = fc.Merge.RequireChecks
+		c.Sources["merge.require-checks"] = source
+	}
+	if fc.Merge.DeleteBranch != nil {
+		c.Merge.DeleteBranch = fc.Merge.DeleteBranch
+		c.Sources["merge.delete-branch"] = source
+	}
+
+	if len(fc.Exclude.Labels) > 0 {
+		c.Exclude.Labels = fc.Exclude.Labels
+		c.Sources["exclude.labels"] = source
+	}
+	if len(fc.Exclude.Repos) > 0 {
+		c.Exclude.Repos = fc.Exclude.Repos
+		c.Sources["exclude.repos"] = source
+	}
+
+	if len(fc.Overrides) > 0 {
+		if c.Overrides == nil {
+			c.Overrides = make(map[string]Override)
+		}
+		maps.Copy(c.Overrides, fc.Overrides)
+		for repo := range fc.Overrides {
+			c.Sources["overrides."+repo] = source
+		}
+	}
+
+	if fc.Policy.Default != "" {
+		c.Policy.Default = fc.Policy.Default
+		c.Sources["policy.default"] = source
+	}
+	if len(fc.Policy.Rules) > 0 {
+		rules := slices.Clone(fc.Policy.Rules)
+		for i := range rules {
+			rules[i].Source = source
+		}
+		c.Policy.Rules = append(c.Policy.Rules, rules...)
+		c.Sources["policy.rules"] = source
+	}
+
+	// Profiles of the same name replace each other as a whole
+	if len(fc.Profiles) > 0 {
+		if c.Profiles == nil {
+			c.Profiles = make(map[string]Profile)
+		}
+		maps.Copy(c.Profiles, fc.Profiles)
+		for name := range fc.Profiles {
+			c.Sources["profiles."+name] = source
+		}
+	}
+}
+
+// applyRepo layers a repo config file. Its policy can only tighten the
+// user's, and unless the user trusts repo files, only its scope and
+// exclusions apply besides.
+func (c *Config) applyRepo(fc *fileConfig, source string) {
+	layer := *fc
+	layer.Policy = policy.Policy{}
+	layer.TrustRepoConfig = nil
+	if !c.TrustRepoConfig {
+		layer = fileConfig{Owner: fc.Owner, Repos: fc.Repos, Exclude: fc.Exclude}
+		for _, ignored := range []struct {
+			key string
+			set bool
+		}{
+			{"patterns", len(fc.Patterns) > 0},
+			{"bots", len(fc.Bots) > 0},
+			{"merge", fc.Merge != MergeSettings{}},
+			{"overrides", len(fc.Overrides) > 0},
+			{"profiles", len(fc.Profiles) > 0},
+		} {
+			if ignored.set {
+				c.Ignored = append(c.Ignored, ignored.key)
 			}
 		}
 	}
+	c.apply(&layer, source)
+	c.tightenPolicy(fc.Policy, source)
+}
 
-	return cfg, nil
+// tightenPolicy adds a repo file's policy without loosening the user's: its
+// rules are evaluated after the user's, only those at least as strict as
+// the default apply, and none can waive CI. Its default only applies if
+// it's stricter.
+func (c *Config) tightenPolicy(p policy.Policy, source string) {
+	if p.Default != "" {
+		if p.Default.AtLeastAsStrict(c.Policy.Default) {
+			c.Policy.Default = p.Default
+			c.Sources["policy.default"] = source
+		} else {
+			c.Ignored = append(c.Ignored, "policy.default")
+		}
+	}
+
+	added := false
+	for i, r := range p.Rules {
+		if !r.Decision.AtLeastAsStrict(c.Policy.Default) {
+			name := r.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			c.Ignored = append(c.Ignored, "policy.rules."+name)
+			continue
+		}
+		if r.RequireChecks != nil && !*r.RequireChecks {
+			r.RequireChecks = nil
+		}
+		r.Source = source
+		c.Policy.Rules = append(c.Policy.Rules, r)
+		added = true
+	}
+	if added {
+		c.Sources["policy.rules"] = source
+	}
+}
+
+// splitList splits a comma-separated gh config value, trimming blanks
+func splitList(value string) []string {
+	var values []string
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// parseBotEntry parses a dep.bots entry of the form name[:login[:branch-prefix]],
+// e.g. "renovate:acme-renovate[bot]" or "snyk"
+func parseBotEntry(entry string) bots.Bot {
+	parts := strings.SplitN(entry, ":", 3)
+	b := bots.Bot{Name: strings.TrimSpace(parts[0])}
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+		b.Logins = []string{strings.TrimSpace(parts[1])}
+	}
+	if len(parts) > 2 {
+		b.BranchPrefix = strings.TrimSpace(parts[2])
+	}
+	return b
+}
+
+// Validate reports the first invalid setting, naming where it was set
+func (c *Config) Validate() error {
+	if strings.Contains(c.Owner, "/") {
+		return c.invalid("owner", fmt.Errorf("invalid owner %q (expected a user or organization name)", c.Owner))
+	}
+	for _, repo := range c.Repos {
+		if !validRepo(repo) {
+			return c.invalid("repos", fmt.Errorf("invalid repo %q (expected OWNER/REPO)", repo))
+		}
+	}
+
+	for _, pattern := range c.Patterns {
+		if err := parser.ValidatePattern(pattern); err != nil {
+			return c.invalid("patterns", err)
+		}
+	}
+
+	if _, err := c.BotRegistry(); err != nil {
+		return c.invalid("bots", err)
+	}
+	for _, b := range c.Bots {
+		for _, pattern := range b.TitlePatterns {
+			if err := parser.ValidatePattern(pattern); err != nil {
+				return c.invalid("bots", fmt.Errorf("bot %q: %w", b.Name, err))
+			}
+		}
+	}
+
+	if err := c.Merge.validate(); err != nil {
+		return c.invalid("merge.method", err)
+	}
+
+	for _, repo := range c.Exclude.Repos {
+		if !validExcludeRepo(repo) {
+			return c.invalid("exclude.repos", fmt.Errorf("invalid repo %q (expected OWNER/REPO, REPO, or a glob such as myorg/legacy-*)", repo))
+		}
+	}
+
+	for _, repo := range slices.Sorted(maps.Keys(c.Overrides)) {
+		o := c.Overrides[repo]
+		key := "overrides." + repo
+		if !validRepoGlob(repo) {
+			return c.invalid(key, fmt.Errorf("invalid repo %q (expected OWNER/REPO or a glob such as myorg/legacy-*)", repo))
+		}
+		if err := o.validate(); err != nil {
+			return c.invalid(key, err)
+		}
+	}
+
+	if err := c.Policy.Validate(); err != nil {
+		return c.invalid("policy", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		if err := c.Profiles[name].validate(c); err != nil {
+			return c.invalid("profiles."+name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m MergeSettings) validate() error {
+	if m.Method == "" {
+		return nil
+	}
+	for _, method := range MergeMethods {
+		if m.Method == method {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid merge method %q (expected one of %s)", m.Method, strings.Join(MergeMethods, ", "))
+}
+
+// invalid wraps a validation error with the setting and its source
+func (c *Config) invalid(key string, err error) error {
+	if source := c.Sources[key]; source != "" {
+		return fmt.Errorf("%s (from %s): %w", key, source, err)
+	}
+	return fmt.Errorf("%s: %w", key, err)
+}
+
+// repoName matches OWNER/REPO
+var repoName = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+
+func validRepo(repo string) bool {
+	return repoName.MatchString(repo)
+}
+
+// validExcludeRepo reports whether repo is an OWNER/REPO glob or a glob
+// matching repo names under any owner
+func validExcludeRepo(repo string) bool {
+	if strings.Contains(repo, "/") {
+		return validRepoGlob(repo)
+	}
+	_, err := path.Match(repo, "")
+	return repo != "" && err == nil
+}
+
+// BotRegistry returns the built-in bots merged with the configured ones
+func (c *Config) BotRegistry() (*bots.Registry, error) {
+	if c == nil {
+		return bots.Builtin(), nil
+	}
+	return bots.NewRegistry(c.Bots)
+}
+
+// MergeFor returns the merge settings for a repo: the defaults with the
+// repo's overrides applied
+func (c *Config) MergeFor(repo string) MergeSettings {
+	if c == nil {
+		return MergeSettings{}
+	}
+
+	return c.Merge.with(c.OverrideFor(repo).Merge)
+}
+
+// GetOwner returns the configured owner or "" if not set
+func (c *Config) GetOwner() string {
+	return c.Owner
 }
 
 // GetRepos returns the configured repos or nil if not set
@@ -0,0 +1,452 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	path := writeConfig(t, `
+owner: myorg
+patterns:
+  - '(?i)upgrade (?P<package>\S+) to (?P<to>\S+), please'
+bots:
+  - name: renovate
+    logins: ['acme-renovate[bot]']
+merge:
+  method: rebase
+  require-checks: true
+overrides:
+  myorg/legacy:
+    merge:
+      require-checks: false
+`)
+
+	fc, err := readFile(path)
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+
+	cfg := &Config{Sources: make(map[string]string)}
+	cfg.apply(fc, path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	// Patterns may contain commas, unlike dep.patterns
+	if len(cfg.Patterns) != 1 || !strings.Contains(cfg.Patterns[0], ", please") {
+		t.Errorf("Patterns = %v", cfg.Patterns)
+	}
+	if cfg.Owner != "myorg" || cfg.Sources["owner"] != path {
+		t.Errorf("Owner = %q from %q", cfg.Owner, cfg.Sources["owner"])
+	}
+
+	settings := cfg.MergeFor("myorg/legacy")
+	if settings.Method != "rebase" || settings.RequireChecks == nil || *settings.RequireChecks {
+		t.Errorf("MergeFor(myorg/legacy) = %+v, want rebase without checks", settings)
+	}
+	if settings := cfg.MergeFor("myorg/app"); settings.RequireChecks == nil || !*settings.RequireChecks {
+		t.Errorf("MergeFor(myorg/app) = %+v, want the defaults", settings)
+	}
+}
+
+func TestReadFileRejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "merge:\n  mehtod: rebase\n")
+
+	_, err := readFile(path)
+	if err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+	if !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), "line 2: field mehtod not found") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Contains(err.Error(), "fileConfig") {
+		t.Errorf("error mentions Go types: %v", err)
+	}
+}
+
+func TestApplyLayers(t *testing.T) {
+	user, err := readFile(writeConfig(t, "repos: [myorg/app]\nmerge:\n  method: rebase\n"))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+	repo, err := readFile(writeConfig(t, "owner: otherorg\n"))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+
+	cfg := &Config{Sources: make(map[string]string), Patterns: []string{"from-gh-config"}}
+	cfg.apply(user, "user")
+	cfg.applyRepo(repo, "repo")
+
+	// The repo file's owner replaces the user file's repos
+	if cfg.Owner != "otherorg" || len(cfg.Repos) != 0 {
+		t.Errorf("scope = %q %v, want otherorg only", cfg.Owner, cfg.Repos)
+	}
+	if cfg.Merge.Method != "rebase" || cfg.Sources["merge.method"] != "user" {
+		t.Errorf("merge.method = %q from %q", cfg.Merge.Method, cfg.Sources["merge.method"])
+	}
+	if len(cfg.Patterns) != 1 || cfg.Patterns[0] != "from-gh-config" {
+		t.Errorf("Patterns = %v, want the gh config fallback kept", cfg.Patterns)
+	}
+}
+
+func TestValidateNamesSource(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{
+			name: "merge method",
+			cfg:  &Config{Merge: MergeSettings{Method: "fast"}, Sources: map[string]string{"merge.method": "config.yml"}},
+			want: `merge.method (from config.yml): invalid merge method "fast"`,
+		},
+		{
+			name: "repo",
+			cfg:  &Config{Repos: []string{"app"}},
+			want: `repos: invalid repo "app"`,
+		},
+		{
+			name: "override",
+			cfg:  &Config{Overrides: map[string]Override{"legacy-*": {}}},
+			want: "overrides.legacy-*",
+		},
+		{
+			name: "exclude repo",
+			cfg:  &Config{Exclude: Exclusions{Repos: []string{"myorg/app/docs"}}, Sources: map[string]string{"exclude.repos": "config.yml"}},
+			want: `exclude.repos (from config.yml): invalid repo "myorg/app/docs"`,
+		},
+		{
+			name: "pattern",
+			cfg:  &Config{Patterns: []string{"(?P<package>"}, Sources: map[string]string{"patterns": "gh config dep.patterns"}},
+			want: "patterns (from gh config dep.patterns)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	fc, err := readFile(writeConfig(t, `
+owner: myorg
+merge:
+  method: merge
+profiles:
+  frontend:
+    repos: [myorg/web, myorg/app]
+    bot: renovate
+    limit: 50
+    merge:
+      method: squash
+  platform:
+    owner: platform
+`))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+
+	cfg := &Config{Sources: make(map[string]string)}
+	cfg.apply(fc, "config.yml")
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if err := cfg.UseProfile("frontend"); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if cfg.Owner != "" || len(cfg.Repos) != 2 {
+		t.Errorf("scope = %q %v, want the profile's repos", cfg.Owner, cfg.Repos)
+	}
+	if cfg.Merge.Method != "squash" || cfg.Sources["merge.method"] != "profile frontend" {
+		t.Errorf("merge.method = %q from %q", cfg.Merge.Method, cfg.Sources["merge.method"])
+	}
+	if cfg.Profile == nil || cfg.Profile.Name != "frontend" || cfg.Profile.Bot != "renovate" || cfg.Profile.Limit != 50 {
+		t.Errorf("Profile = %+v", cfg.Profile)
+	}
+
+	err = cfg.UseProfile("backend")
+	if err == nil || !strings.Contains(err.Error(), "available: frontend, platform") {
+		t.Errorf("UseProfile(backend) = %v, want the available profiles listed", err)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		want    string
+	}{
+		{name: "bot", profile: Profile{Bot: "dependabott"}, want: "profiles.p: invalid bot"},
+		{name: "repo", profile: Profile{Repos: []string{"web"}}, want: `profiles.p: invalid repo "web"`},
+		{name: "merge method", profile: Profile{Merge: MergeSettings{Method: "fast"}}, want: `profiles.p: invalid merge method "fast"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Profiles: map[string]Profile{"p": tt.profile}}
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAndUnset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	t.Setenv("GH_CONFIG_DIR", filepath.Join(dir, "gh"))
+	t.Setenv("GH_DEP_CONFIG", path)
+	if err := os.WriteFile(path, []byte("# team defaults\nowner: myorg\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	for key, values := range map[string][]string{
+		"repos":                                  {"myorg/app,myorg/api"},
+		"overrides.myorg/legacy.merge.method":    {"rebase"},
+		"profiles.frontend.merge.require-checks": {"true"},
+	} {
+		if err := Set(path, key, values); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key, err)
+		}
+	}
+
+	// Invalid values leave the file untouched
+	before, _ := os.ReadFile(path)
+	if err := Set(path, "merge.method", []string{"fast"}); err == nil {
+		t.Error("expected an error for an invalid merge method")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Errorf("file changed after a rejected set:\n%s", after)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// repos replaces owner in the same file
+	if cfg.Owner != "" || len(cfg.Repos) != 2 {
+		t.Errorf("scope = %q %v, want the set repos only", cfg.Owner, cfg.Repos)
+	}
+	if setting, _ := cfg.Get("overrides.myorg/legacy.merge.method"); setting.Value != "rebase" || setting.Source != path {
+		t.Errorf("override = %+v", setting)
+	}
+	if !strings.Contains(string(before), "# team defaults\n") {
+		t.Errorf("comments were dropped:\n%s", before)
+	}
+
+	removed, err := Unset(path, "overrides.myorg/legacy.merge.method")
+	if err != nil || !removed {
+		t.Fatalf("Unset = %t, %v", removed, err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "overrides") {
+		t.Errorf("empty overrides were left behind:\n%s", data)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "merge.method", want: "merge/method"},
+		{key: "overrides.myorg/my.app.merge.require-checks", want: "overrides/myorg/my.app/merge/require-checks"},
+		{key: "profiles.frontend.limit", want: "profiles/frontend/limit"},
+		{key: "profiles.frontend.color", wantErr: true},
+		{key: "bots", wantErr: true},
+		{key: "mege.method", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			path, _, err := parseKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKey(%q) error = %v, wantErr %t", tt.key, err, tt.wantErr)
+			}
+			if got := strings.Join(path, "/"); !tt.wantErr && got != tt.want {
+				t.Errorf("parseKey(%q) = %s, want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPolicyLayers(t *testing.T) {
+	user, err := readFile(writeConfig(t, `
+policy:
+  default: needs-review
+  rules:
+    - name: no-majors
+      match: {update-types: [major]}
+      decision: deny
+`))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+	repo, err := readFile(writeConfig(t, `
+policy:
+  rules:
+    - name: typescript-majors
+      match: {packages: [typescript]}
+      decision: needs-review
+      approvals: 2
+      require-checks: false
+    - name: allow-everything
+      decision: allow
+  default: allow
+`))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+
+	cfg := &Config{Sources: make(map[string]string)}
+	cfg.apply(user, "user")
+	cfg.applyRepo(repo, "repo")
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	// The repo file's rules are evaluated after the user file's, and only
+	// those that don't loosen the user's default apply
+	var names []string
+	for _, r := range cfg.Policy.Rules {
+		names = append(names, r.Name+"@"+r.Source)
+	}
+	if strings.Join(names, ",") != "no-majors@user,typescript-majors@repo" {
+		t.Errorf("rules = %v", names)
+	}
+	if r := cfg.Policy.Rules[1]; r.RequireChecks != nil {
+		t.Errorf("repo rule require-checks = %v, want it dropped", *r.RequireChecks)
+	}
+	if cfg.Policy.Default != "needs-review" {
+		t.Errorf("default = %q, want the user file's", cfg.Policy.Default)
+	}
+	if strings.Join(cfg.Ignored, ",") != "policy.default,policy.rules.allow-everything" {
+		t.Errorf("Ignored = %v", cfg.Ignored)
+	}
+}
+
+func TestApplyRepoTrust(t *testing.T) {
+	repo, err := readFile(writeConfig(t, `
+owner: otherorg
+exclude: {labels: [wip]}
+patterns: ['^Update (?P<name>\S+) to (?P<to>\S+)$']
+merge: {method: merge, require-checks: false}
+overrides:
+  otherorg/app: {modes: [approve, merge]}
+bots:
+  - name: acme
+    logins: ["acme[bot]"]
+policy:
+  default: deny
+`))
+	if err != nil {
+		t.Fatalf("readFile failed: %v", err)
+	}
+
+	cfg := &Config{Sources: make(map[string]string), Merge: MergeSettings{Method: "squash"}}
+	cfg.applyRepo(repo, "repo")
+
+	if cfg.Owner != "otherorg" || len(cfg.Exclude.Labels) != 1 || cfg.Policy.Default != "deny" {
+		t.Errorf("scope, exclusions and policy not applied: %+v", cfg)
+	}
+	if cfg.Merge.Method != "squash" || cfg.Merge.RequireChecks != nil || len(cfg.Overrides) != 0 || len(cfg.Bots) != 0 || len(cfg.Patterns) != 0 {
+		t.Errorf("untrusted settings applied: %+v", cfg)
+	}
+	if strings.Join(cfg.Ignored, ",") != "patterns,bots,merge,overrides" {
+		t.Errorf("Ignored = %v", cfg.Ignored)
+	}
+
+	trusted := &Config{Sources: make(map[string]string), TrustRepoConfig: true}
+	trusted.applyRepo(repo, "repo")
+	if trusted.Merge.Method != "merge" || len(trusted.Overrides) != 1 || len(trusted.Bots) != 1 || len(trusted.Ignored) != 0 {
+		t.Errorf("trusted repo settings not applied: %+v", trusted)
+	}
+}
+
+func TestOverrideFor(t *testing.T) {
+	enabled, disabled := true, false
+	cfg := &Config{
+		Merge: MergeSettings{Method: "squash", RequireChecks: &enabled},
+		Overrides: map[string]Override{
+			"myorg/*":          {Merge: MergeSettings{DeleteBranch: &enabled}},
+			"myorg/legacy-*":   {Merge: MergeSettings{Method: "rebase", RequireChecks: &disabled}, Modes: []string{ActionApprove}},
+			"myorg/legacy-api": {Merge: MergeSettings{Method: "merge"}},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	tests := []struct {
+		repo         string
+		method       string
+		checks       bool
+		deleteBranch bool
+		canMerge     bool
+	}{
+		{repo: "myorg/app", method: "squash", checks: true, deleteBranch: true, canMerge: true},
+		{repo: "MyOrg/Legacy-Web", method: "rebase", checks: false, deleteBranch: true, canMerge: false},
+		// The exact key wins for the method; the glob still applies otherwise
+		{repo: "myorg/legacy-api", method: "merge", checks: false, deleteBranch: true, canMerge: false},
+		{repo: "other/app", method: "squash", checks: true, deleteBranch: false, canMerge: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			settings := cfg.MergeFor(tt.repo)
+			deleteBranch := settings.DeleteBranch != nil && *settings.DeleteBranch
+			if settings.Method != tt.method || *settings.RequireChecks != tt.checks || deleteBranch != tt.deleteBranch {
+				t.Errorf("MergeFor(%s) = %s checks=%t delete=%t", tt.repo, settings.Method, *settings.RequireChecks, deleteBranch)
+			}
+			if got := cfg.Allows(tt.repo, ActionMerge); got != tt.canMerge {
+				t.Errorf("Allows(%s, merge) = %t, want %t", tt.repo, got, tt.canMerge)
+			}
+			if !cfg.Allows(tt.repo, ActionApprove) {
+				t.Errorf("Allows(%s, approve) = false", tt.repo)
+			}
+		})
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	tests := []struct {
+		key      string
+		override Override
+		want     string
+	}{
+		{key: "myorg", want: "invalid repo"},
+		{key: "myorg/[legacy", want: "invalid repo"},
+		{key: "myorg/*", override: Override{Modes: []string{"close"}}, want: `invalid mode "close"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cfg := &Config{Overrides: map[string]Override{tt.key: tt.override}}
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Set writes a setting to the config file at path, creating the file if
+// needed. List settings take several values; repos and authors may also be
+// comma-separated. The change is rolled back if the resulting
+// configuration doesn't validate.
+func Set(path, key string, values []string) error {
+	keyPath, kind, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+
+	value, err := valueNode(keyPath, kind, values)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	return editFile(path, func(root *yaml.Node) error {
+		parent := root
+		for _, name := range keyPath[:len(keyPath)-1] {
+			parent = ensureMapping(parent, name)
+		}
+		setKey(parent, keyPath[len(keyPath)-1], value)
+
+		// Owner and repos are alternative scopes
+		switch keyPath[len(keyPath)-1] {
+		case "owner":
+			deleteKey(parent, "repos")
+		case "repos":
+			deleteKey(parent, "owner")
+		}
+		return nil
+	})
+}
+
+// Unset removes a setting from the config file at path. Returns false if
+// the file doesn't set it.
+func Unset(path, key string) (bool, error) {
+	keyPath, _, err := parseKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	removed := false
+	err = editFile(path, func(root *yaml.Node) error {
+		removed = unsetPath(root, keyPath)
+		return nil
+	})
+	return removed, err
+}
+
+// editFile applies fn to the config file at path and writes it back,
+// restoring the previous content if the configuration no longer loads
+func editFile(path string, fn func(root *yaml.Node) error) error {
+	data, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: expected a mapping at the top level", path)
+	}
+
+	if err := fn(root); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if _, err := Load(); err != nil {
+		if existed {
+			_ = os.WriteFile(path, data, 0644)
+		} else {
+			_ = os.Remove(path)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// valueNode builds the YAML node for a setting's values
+func valueNode(keyPath []string, kind valueKind, values []string) (*yaml.Node, error) {
+	if kind == kindList {
+		// Patterns may contain commas, other lists are comma-separated
+		if keyPath[len(keyPath)-1] != "patterns" {
+			values = splitList(strings.Join(values, ","))
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("expected at least one value")
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, v := range values {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+		}
+		return node, nil
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected a single value, got %d", len(values))
+	}
+	value := values[0]
+
+	switch kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}, nil
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(n)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	}
+}
+
+// lookupKey returns the index of key's key node in a mapping, or -1
+func lookupKey(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func setKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	if i := lookupKey(mapping, key); i >= 0 {
+		// Keep comments attached to the old value
+		value.HeadComment = mapping.Content[i+1].HeadComment
+		value.LineComment = mapping.Content[i+1].LineComment
+		mapping.Content[i+1] = value
+		return
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func deleteKey(mapping *yaml.Node, key string) bool {
+	i := lookupKey(mapping, key)
+	if i < 0 {
+		return false
+	}
+	// Keep a comment above the key, e.g. at the top of the file
+	if comment := mapping.Content[i].HeadComment; comment != "" && i+2 < len(mapping.Content) {
+		next := mapping.Content[i+2]
+		next.HeadComment = strings.TrimSpace(comment + "\n" + next.HeadComment)
+	}
+	mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+	return true
+}
+
+// ensureMapping returns the mapping under key, replacing any other value
+func ensureMapping(mapping *yaml.Node, key string) *yaml.Node {
+	if i := lookupKey(mapping, key); i >= 0 && mapping.Content[i+1].Kind == yaml.MappingNode {
+		return mapping.Content[i+1]
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setKey(mapping, key, child)
+	return child
+}
+
+// unsetPath deletes the value at path, then any mappings left empty by it
+func unsetPath(mapping *yaml.Node, path []string) bool {
+	if len(path) == 1 {
+		return deleteKey(mapping, path[0])
+	}
+
+	i := lookupKey(mapping, path[0])
+	if i < 0 || mapping.Content[i+1].Kind != yaml.MappingNode {
+		return false
+	}
+	child := mapping.Content[i+1]
+	if !unsetPath(child, path[1:]) {
+		return false
+	}
+	if len(child.Content) == 0 {
+		deleteKey(mapping, path[0])
+	}
+	return true
+}
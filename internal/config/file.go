@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the schema of config.yml and .github/gh-dep.yml
+type fileConfig struct {
+	Owner     string              `yaml:"owner"`
+	Repos     []string            `yaml:"repos"`
+	Patterns  []string            `yaml:"patterns"`
+	Bots      []fileBot           `yaml:"bots"`
+	Merge     MergeSettings       `yaml:"merge"`
+	Exclude   Exclusions          `yaml:"exclude"`
+	Overrides map[string]Override `yaml:"overrides"`
+	Profiles  map[string]Profile  `yaml:"profiles"`
+	Policy    policy.Policy       `yaml:"policy"`
+
+	// TrustRepoConfig, in the user file, lets repo files change more than
+	// the scope and exclusions, and tighten the policy
+	TrustRepoConfig *bool `yaml:"trust-repo-config"`
+}
+
+// fileBot is a bot definition in a config file
+type fileBot struct {
+	Name          string       `yaml:"name"`
+	Logins        []string     `yaml:"logins"`
+	TitlePatterns []string     `yaml:"title-patterns"`
+	BranchPrefix  string       `yaml:"branch-prefix"`
+	Commands      fileCommands `yaml:"commands"`
+}
+
+type fileCommands struct {
+	Rebase      string `yaml:"rebase"`
+	Recreate    string `yaml:"recreate"`
+	RebaseLabel string `yaml:"rebase-label"`
+}
+
+func (b fileBot) bot() bots.Bot {
+	return bots.Bot{
+		Name:          b.Name,
+		Logins:        b.Logins,
+		TitlePatterns: b.TitlePatterns,
+		BranchPrefix:  b.BranchPrefix,
+		Commands: bots.Commands{
+			Rebase:      b.Commands.Rebase,
+			Recreate:    b.Commands.Recreate,
+			RebaseLabel: b.Commands.RebaseLabel,
+		},
+	}
+}
+
+// UserConfigPath returns the path of the user config file:
+// $GH_DEP_CONFIG, or config.yml under $XDG_CONFIG_HOME/gh-dep (~/.config/gh-dep)
+func UserConfigPath() (string, error) {
+	if path := os.Getenv("GH_DEP_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configDir, "gh-dep", "config.yml"), nil
+}
+
+// RepoConfigPath returns the path of .github/gh-dep.yml in the git
+// repository containing the working directory, or "" outside a repository
+func RepoConfigPath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return filepath.Join(dir, ".github", "gh-dep.yml")
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readFile decodes a config file, rejecting unknown keys. Returns nil if
+// the file doesn't exist.
+func readFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %s", path, yamlError(err))
+	}
+
+	return &fc, nil
+}
+
+// yamlTypeName matches the Go type names yaml.v3 puts in its errors
+var yamlTypeName = regexp.MustCompile(` in type [\w.\[\]*]+`)
+
+// yamlError turns a yaml.v3 error into a message that talks about the
+// file rather than Go types, e.g. "line 3: field mege not found"
+func yamlError(err error) string {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			msgs[i] = yamlTypeName.ReplaceAllString(msg, "")
+		}
+		return strings.Join(msgs, "; ")
+	}
+	return err.Error()
+}
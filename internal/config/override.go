@@ -0,0 +1,108 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+)
+
+// OverrideFor returns the combined overrides whose keys match a repo. When
+// several match, the most specific one wins for each setting: an exact
+// OWNER/REPO over globs, and longer globs over shorter ones.
+func (c *Config) OverrideFor(repo string) Override {
+	if c == nil {
+		return Override{}
+	}
+
+	var matching []string
+	for key := range c.Overrides {
+		if matchRepo(key, repo) {
+			matching = append(matching, key)
+		}
+	}
+	slices.SortFunc(matching, func(a, b string) int {
+		return cmp.Or(cmp.Compare(specificity(a), specificity(b)), strings.Compare(a, b))
+	})
+
+	var combined Override
+	for _, key := range matching {
+		o := c.Overrides[key]
+		combined.Merge = combined.Merge.with(o.Merge)
+		if len(o.Modes) > 0 {
+			combined.Modes = o.Modes
+		}
+	}
+	return combined
+}
+
+// Allows reports whether gh-dep may take an action (approve or merge) on
+// the repo's PRs
+func (c *Config) Allows(repo, action string) bool {
+	modes := c.OverrideFor(repo).Modes
+	return len(modes) == 0 || slices.Contains(modes, action)
+}
+
+// CheckAllowed returns an error if the repo's overrides don't allow an action
+func (c *Config) CheckAllowed(repo, action string) error {
+	if c.Allows(repo, action) {
+		return nil
+	}
+	return fmt.Errorf("%s is not allowed on %s by its config overrides (modes: %s)", action, repo, strings.Join(c.OverrideFor(repo).Modes, ", "))
+}
+
+// with returns the settings with the ones set in o applied over them
+func (m MergeSettings) with(o MergeSettings) MergeSettings {
+	if o.Method != "" {
+		m.Method = o.Method
+	}
+	if o.RequireChecks != nil {
+		m.RequireChecks = o.RequireChecks
+	}
+	if o.DeleteBranch != nil {
+		m.DeleteBranch = o.DeleteBranch
+	}
+	return m
+}
+
+func (o Override) validate() error {
+	if err := o.Merge.validate(); err != nil {
+		return err
+	}
+	for _, mode := range o.Modes {
+		if mode != ActionApprove && mode != ActionMerge {
+			return fmt.Errorf("invalid mode %q (expected %s or %s)", mode, ActionApprove, ActionMerge)
+		}
+	}
+	return nil
+}
+
+// matchRepo reports whether an override key (OWNER/REPO or a glob) matches
+// a repo, ignoring case as GitHub does
+func matchRepo(key, repo string) bool {
+	ok, _ := path.Match(strings.ToLower(key), strings.ToLower(repo))
+	return ok
+}
+
+// specificity ranks override keys: globs by their number of literal
+// characters, exact keys above every glob
+func specificity(key string) int {
+	if !strings.ContainsAny(key, "*?[") {
+		return math.MaxInt
+	}
+	return len(key) - strings.Count(key, "*") - strings.Count(key, "?")
+}
+
+// validRepoGlob reports whether key is OWNER/REPO or a valid glob over them
+func validRepoGlob(key string) bool {
+	if validRepo(key) {
+		return true
+	}
+	if strings.Count(key, "/") != 1 || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") {
+		return false
+	}
+	_, err := path.Match(key, "")
+	return err == nil
+}
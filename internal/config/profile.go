@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Profile is a named set of defaults selected with --profile, e.g. one per
+// team. Empty values leave the rest of the configuration in place.
+type Profile struct {
+	Name    string        `yaml:"-"`
+	Owner   string        `yaml:"owner"`
+	Repos   []string      `yaml:"repos"`
+	Bot     string        `yaml:"bot"`     // same values as --bot
+	Authors []string      `yaml:"authors"` // author logins; override bot
+	Label   string        `yaml:"label"`
+	Limit   int           `yaml:"limit"`
+	Merge   MergeSettings `yaml:"merge"`
+}
+
+// UseProfile selects a profile: its scope and merge settings replace the
+// configured ones, and its search defaults become available through
+// c.Profile. An empty name selects nothing.
+func (c *Config) UseProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("unknown profile %q (no profiles are configured)", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(slices.Sorted(maps.Keys(c.Profiles)), ", "))
+	}
+	p.Name = name
+	c.Profile = &p
+
+	source := "profile " + name
+	if p.Owner != "" || len(p.Repos) > 0 {
+		c.Owner = p.Owner
+		c.Repos = p.Repos
+		c.Sources["owner"] = source
+		c.Sources["repos"] = source
+	}
+	if p.Merge.Method != "" {
+		c.Merge.Method = p.Merge.Method
+		c.Sources["merge.method"] = source
+	}
+	if p.Merge.RequireChecks != nil {
+		c.Merge.RequireChecks = p.Merge.RequireChecks
+		c.Sources["merge.require-checks"] = source
+	}
+	if p.Merge.DeleteBranch != nil {
+		c.Merge.DeleteBranch = p.Merge.DeleteBranch
+		c.Sources["merge.delete-branch"] = source
+	}
+
+	return nil
+}
+
+// validate checks the values of a profile
+func (p Profile) validate(c *Config) error {
+	if strings.Contains(p.Owner, "/") {
+		return fmt.Errorf("invalid owner %q (expected a user or organization name)", p.Owner)
+	}
+	for _, repo := range p.Repos {
+		if !validRepo(repo) {
+			return fmt.Errorf("invalid repo %q (expected OWNER/REPO)", repo)
+		}
+	}
+
+	if p.Bot != "" {
+		registry, err := c.BotRegistry()
+		if err != nil {
+			return err
+		}
+		if _, err := registry.Resolve(p.Bot); err != nil {
+			return fmt.Errorf("invalid bot: %w", err)
+		}
+	}
+
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid limit %d (must not be negative)", p.Limit)
+	}
+
+	return p.Merge.validate()
+}
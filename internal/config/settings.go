@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/policy"
+)
+
+// Setting is an effective setting and where its value came from
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// SourceDefault is the source of settings nothing configures
+const SourceDefault = "default"
+
+// DefaultMergeMethod is the merge method used when none is configured
+const DefaultMergeMethod = "squash"
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindList
+	kindBool
+	kindInt
+)
+
+// Keys that can be read and written with their value kinds. Override and
+// profile keys are prefixed with overrides.OWNER/REPO. or profiles.NAME.
+var (
+	topKeys = map[string]valueKind{
+		"owner":                kindString,
+		"repos":                kindList,
+		"patterns":             kindList,
+		"merge.method":         kindString,
+		"merge.require-checks": kindBool,
+		"merge.delete-branch":  kindBool,
+		"exclude.labels":       kindList,
+		"exclude.repos":        kindList,
+		"trust-repo-config":    kindBool,
+	}
+	overrideKeys = map[string]valueKind{
+		"merge.method":         kindString,
+		"merge.require-checks": kindBool,
+		"merge.delete-branch":  kindBool,
+		"modes":                kindList,
+	}
+	profileKeys = map[string]valueKind{
+		"owner":                kindString,
+		"repos":                kindList,
+		"bot":                  kindString,
+		"authors":              kindList,
+		"label":                kindString,
+		"limit":                kindInt,
+		"merge.method":         kindString,
+		"merge.require-checks": kindBool,
+		"merge.delete-branch":  kindBool,
+	}
+
+	// fileOnlyKeys can be read but only changed by editing a config file
+	fileOnlyKeys = []string{"bots", "policy.default", "policy.rules"}
+)
+
+// parseKey maps a setting key such as "overrides.myorg/app.merge.method" to
+// its path in a config file and the kind of value it holds
+func parseKey(key string) ([]string, valueKind, error) {
+	if kind, ok := topKeys[key]; ok {
+		return strings.Split(key, "."), kind, nil
+	}
+
+	for _, section := range []struct {
+		name string
+		keys map[string]valueKind
+	}{
+		{"overrides", overrideKeys},
+		{"profiles", profileKeys},
+	} {
+		rest, ok := strings.CutPrefix(key, section.name+".")
+		if !ok {
+			continue
+		}
+		for suffix, kind := range section.keys {
+			if name, ok := strings.CutSuffix(rest, "."+suffix); ok && name != "" {
+				return append([]string{section.name, name}, strings.Split(suffix, ".")...), kind, nil
+			}
+		}
+		return nil, 0, fmt.Errorf("unknown key %q (expected %s.<name>.<%s>)", key, section.name, strings.Join(slices.Sorted(maps.Keys(section.keys)), "|"))
+	}
+
+	if slices.Contains(fileOnlyKeys, key) {
+		return nil, 0, fmt.Errorf("%s can't be set from the command line; edit the config file", key)
+	}
+
+	return nil, 0, fmt.Errorf("unknown key %q (expected one of %s, overrides.<repo>.<key>, profiles.<name>.<key>)", key, strings.Join(slices.Sorted(maps.Keys(topKeys)), ", "))
+}
+
+// ValidKey reports whether key names a setting
+func ValidKey(key string) error {
+	if slices.Contains(fileOnlyKeys, key) {
+		return nil
+	}
+	_, _, err := parseKey(key)
+	return err
+}
+
+// Settings lists the effective settings with their sources, including
+// defaults for what isn't configured
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	add := func(key string, value any, source string) {
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	}
+
+	switch {
+	case c.Owner != "":
+		add("owner", c.Owner, c.Sources["owner"])
+	case len(c.Repos) == 0:
+		add("owner", "@me", SourceDefault)
+	default:
+		add("owner", nil, c.Sources["owner"])
+	}
+	add("repos", listValue(c.Repos), c.Sources["repos"])
+	add("patterns", listValue(c.Patterns), c.Sources["patterns"])
+
+	var botNames []string
+	if registry, err := c.BotRegistry(); err == nil {
+		botNames = registry.Names()
+	}
+	add("bots", botNames, c.Sources["bots"])
+
+	if c.Merge.Method != "" {
+		add("merge.method", c.Merge.Method, c.Sources["merge.method"])
+	} else {
+		add("merge.method", DefaultMergeMethod, SourceDefault)
+	}
+	add("merge.require-checks", boolValue(c.Merge.RequireChecks), c.Sources["merge.require-checks"])
+	add("merge.delete-branch", boolValue(c.Merge.DeleteBranch), c.Sources["merge.delete-branch"])
+	add("exclude.labels", listValue(c.Exclude.Labels), c.Sources["exclude.labels"])
+	add("exclude.repos", listValue(c.Exclude.Repos), c.Sources["exclude.repos"])
+
+	for _, repo := range slices.Sorted(maps.Keys(c.Overrides)) {
+		o := c.Overrides[repo]
+		source := c.Sources["overrides."+repo]
+		prefix := "overrides." + repo + "."
+		if o.Merge.Method != "" {
+			add(prefix+"merge.method", o.Merge.Method, source)
+		}
+		if o.Merge.RequireChecks != nil {
+			add(prefix+"merge.require-checks", *o.Merge.RequireChecks, source)
+		}
+		if o.Merge.DeleteBranch != nil {
+			add(prefix+"merge.delete-branch", *o.Merge.DeleteBranch, source)
+		}
+		if len(o.Modes) > 0 {
+			add(prefix+"modes", o.Modes, source)
+		}
+	}
+
+	add("trust-repo-config", c.TrustRepoConfig, c.Sources["trust-repo-config"])
+
+	if c.Policy.Default != "" {
+		add("policy.default", string(c.Policy.Default), c.Sources["policy.default"])
+	} else {
+		add("policy.default", string(policy.Allow), SourceDefault)
+	}
+	var rules []string
+	for _, r := range c.Policy.Rules {
+		rules = append(rules, r.Name)
+	}
+	add("policy.rules", listValue(rules), c.Sources["policy.rules"])
+
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		source := c.Sources["profiles."+name]
+		prefix := "profiles." + name + "."
+		for _, field := range []struct {
+			key   string
+			value any
+			set   bool
+		}{
+			{"owner", p.Owner, p.Owner != ""},
+			{"repos", p.Repos, len(p.Repos) > 0},
+			{"bot", p.Bot, p.Bot != ""},
+			{"authors", p.Authors, len(p.Authors) > 0},
+			{"label", p.Label, p.Label != ""},
+			{"limit", p.Limit, p.Limit != 0},
+			{"merge.method", p.Merge.Method, p.Merge.Method != ""},
+			{"merge.require-checks", boolValue(p.Merge.RequireChecks), p.Merge.RequireChecks != nil},
+			{"merge.delete-branch", boolValue(p.Merge.DeleteBranch), p.Merge.DeleteBranch != nil},
+		} {
+			if field.set {
+				add(prefix+field.key, field.value, source)
+			}
+		}
+	}
+
+	return settings
+}
+
+// Get returns the effective setting for key. Settings that are valid but
+// not configured are returned with a nil value.
+func (c *Config) Get(key string) (Setting, error) {
+	if err := ValidKey(key); err != nil {
+		return Setting{}, err
+	}
+
+	for _, s := range c.Settings() {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	return Setting{Key: key, Source: SourceDefault}, nil
+}
+
+// listValue keeps unset lists as nil rather than an empty list
+func listValue(values []string) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func boolValue(value *bool) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
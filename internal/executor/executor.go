@@ -0,0 +1,297 @@
+// Package executor runs an action on many PRs with bounded concurrency.
+// PRs of the same repo run one at a time in the order given, so two merges
+// into a repo never race; different repos run in parallel. Actions that
+// can't go ahead yet, such as merges waiting for CI, are retried until
+// they do or the wait runs out.
+package executor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// DefaultConcurrency is the number of repos worked on at once when
+// Options.Concurrency isn't set
+const DefaultConcurrency = 4
+
+// DefaultPollInterval is the delay between retries of pending actions when
+// Options.PollInterval isn't set
+const DefaultPollInterval = 30 * time.Second
+
+// Status is the outcome of an action on a PR
+type Status string
+
+const (
+	StatusDone    Status = "done"
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+	StatusPending Status = "pending" // not yet possible; retried while Options.Wait allows
+)
+
+// Result is the outcome of an action on a PR
+type Result struct {
+	PR     types.PR
+	Action string // e.g. approve, or merge (api, squash)
+	Detail string // extra information about a done action, e.g. via API, squash
+	Status Status
+	Err    error // why the action was skipped, failed or is pending
+
+	// Retry, when set on a pending result, runs on the next attempt
+	// instead of the original Func, e.g. to merge without approving again
+	Retry Func
+}
+
+// Done, Skip, Fail and Pending build results
+func Done(pr types.PR, action, detail string) Result {
+	return Result{PR: pr, Action: action, Detail: detail, Status: StatusDone}
+}
+
+func Skip(pr types.PR, action string, err error) Result {
+	return Result{PR: pr, Action: action, Status: StatusSkipped, Err: err}
+}
+
+func Fail(pr types.PR, action string, err error) Result {
+	return Result{PR: pr, Action: action, Status: StatusFailed, Err: err}
+}
+
+func Pending(pr types.PR, action string, err error) Result {
+	return Result{PR: pr, Action: action, Status: StatusPending, Err: err}
+}
+
+// Func performs an action on a PR
+type Func func(pr types.PR) Result
+
+// EventKind tells what an Event reports
+type EventKind int
+
+const (
+	EventStarted  EventKind = iota // an action on a PR started
+	EventFinished                  // an action on a PR finished; Result is set
+	EventPending                   // an action on a PR is pending and will be retried; Result is set
+)
+
+// Event reports progress
+type Event struct {
+	Kind      EventKind
+	PR        types.PR
+	Result    Result
+	Completed int // actions finished so far, including this one
+	Total     int
+}
+
+// Options configure a run
+type Options struct {
+	Concurrency  int           // repos worked on at once (default DefaultConcurrency)
+	Wait         time.Duration // how long to retry pending actions; 0 skips them at once
+	PollInterval time.Duration // delay between retries (default DefaultPollInterval)
+	OnEvent      func(Event)   // called for every event, never concurrently
+
+	// Train runs the PRs of a repo strictly one after another: a pending
+	// PR holds back the rest of its repo, and one that fails or runs out of
+	// Wait skips them
+	Train bool
+}
+
+// Summary is the outcome of a run
+type Summary struct {
+	Results []Result // in the order the PRs were given
+	Done    int
+	Skipped int
+	Failed  int
+	Elapsed time.Duration
+}
+
+// String describes the counts, e.g. "3 done, 1 skipped, 0 failed in 2.1s"
+func (s Summary) String() string {
+	return fmt.Sprintf("%d done, %d skipped, %d failed in %s", s.Done, s.Skipped, s.Failed, s.Elapsed.Round(100*time.Millisecond))
+}
+
+// Run performs fn on every PR and returns once all have finished. Pending
+// results are retried every PollInterval until Wait has passed, after which
+// they count as skipped.
+func Run(prs []types.PR, fn Func, opts Options) Summary {
+	start := time.Now()
+	deadline := start.Add(opts.Wait)
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	results := make([]Result, len(prs))
+	attempts := make([]Func, len(prs))
+	for i := range attempts {
+		attempts[i] = fn
+	}
+
+	var mu sync.Mutex
+	completed := 0
+	emit := func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Kind == EventFinished {
+			completed++
+		}
+		e.Completed = completed
+		e.Total = len(prs)
+		if opts.OnEvent != nil {
+			opts.OnEvent(e)
+		}
+	}
+
+	remaining := make([]int, len(prs))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	// With Train, the pending PR holding back each repo and the failure
+	// that stopped it
+	var trainMu sync.Mutex
+	held := make(map[string]int)
+	stopped := make(map[string]Result)
+
+	for len(remaining) > 0 {
+		retrying := opts.Wait > 0 && time.Now().Before(deadline)
+
+		var pendingMu sync.Mutex
+		var pending []int
+		runRound(prs, remaining, opts.Concurrency, func(i int) {
+			repo := strings.ToLower(prs[i].Repo)
+			if opts.Train {
+				trainMu.Lock()
+				failed, isStopped := stopped[repo]
+				holder, isHeld := held[repo]
+				trainMu.Unlock()
+
+				if isStopped {
+					reason := "failed"
+					if failed.Status == StatusPending {
+						reason = "timed out"
+					}
+					results[i] = Skip(prs[i], failed.Action, fmt.Errorf("train stopped: #%d %s", failed.PR.Number, reason))
+					emit(Event{Kind: EventFinished, PR: prs[i], Result: results[i]})
+					return
+				}
+				if isHeld && holder != i {
+					pendingMu.Lock()
+					pending = append(pending, i)
+					pendingMu.Unlock()
+					return
+				}
+			}
+
+			emit(Event{Kind: EventStarted, PR: prs[i]})
+			r := attempts[i](prs[i])
+			results[i] = r
+
+			if opts.Train {
+				trainMu.Lock()
+				if r.Status == StatusPending && retrying {
+					held[repo] = i
+				} else {
+					delete(held, repo)
+				}
+				if r.Status == StatusFailed || (r.Status == StatusPending && !retrying) {
+					stopped[repo] = r
+				}
+				trainMu.Unlock()
+			}
+
+			if r.Status != StatusPending {
+				emit(Event{Kind: EventFinished, PR: prs[i], Result: r})
+				return
+			}
+			if !retrying {
+				results[i] = giveUp(r, opts.Wait)
+				emit(Event{Kind: EventFinished, PR: prs[i], Result: results[i]})
+				return
+			}
+
+			if r.Retry != nil {
+				attempts[i] = r.Retry
+			}
+			pendingMu.Lock()
+			pending = append(pending, i)
+			pendingMu.Unlock()
+			emit(Event{Kind: EventPending, PR: prs[i], Result: r})
+		})
+
+		// Keep the PRs in the order they were given
+		slices.Sort(pending)
+		remaining = pending
+		if len(remaining) > 0 {
+			time.Sleep(min(interval, max(time.Until(deadline), 0)))
+		}
+	}
+
+	summary := Summary{Results: results, Elapsed: time.Since(start)}
+	for _, r := range results {
+		switch r.Status {
+		case StatusDone:
+			summary.Done++
+		case StatusSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// runRound runs do for each index, one at a time per repo in the given
+// order and at most concurrency repos at once
+func runRound(prs []types.PR, indexes []int, concurrency int, do func(i int)) {
+	// Queue the PRs of each repo in order, repos in order of first appearance
+	var repos [][]int
+	byRepo := make(map[string]int)
+	for _, i := range indexes {
+		key := strings.ToLower(prs[i].Repo)
+		idx, ok := byRepo[key]
+		if !ok {
+			idx = len(repos)
+			byRepo[key] = idx
+			repos = append(repos, nil)
+		}
+		repos[idx] = append(repos[idx], i)
+	}
+
+	workers := concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	workers = min(workers, len(repos))
+
+	queue := make(chan []int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repoIndexes := range queue {
+				for _, i := range repoIndexes {
+					do(i)
+				}
+			}
+		}()
+	}
+	for _, repoIndexes := range repos {
+		queue <- repoIndexes
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// giveUp turns a pending result that can't be retried any longer into a
+// skipped one
+func giveUp(r Result, waited time.Duration) Result {
+	r.Status = StatusSkipped
+	r.Retry = nil
+	if waited > 0 {
+		r.Err = fmt.Errorf("%w; gave up after %s", r.Err, waited)
+	}
+	return r
+}
@@ -0,0 +1,236 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestRunSerializesRepos(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1},
+		{Repo: "org/api", Number: 2},
+		{Repo: "org/app", Number: 3},
+		{Repo: "org/web", Number: 4},
+		{Repo: "org/app", Number: 5},
+	}
+
+	var mu sync.Mutex
+	running := make(map[string]bool)
+	var order []int // order the org/app PRs ran in
+	inFlight, maxInFlight := 0, 0
+
+	fn := func(pr types.PR) Result {
+		mu.Lock()
+		if running[pr.Repo] {
+			t.Errorf("%s#%d started while another PR of the repo was running", pr.Repo, pr.Number)
+		}
+		running[pr.Repo] = true
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		if pr.Repo == "org/app" {
+			order = append(order, pr.Number)
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running[pr.Repo] = false
+		inFlight--
+		mu.Unlock()
+
+		if pr.Number == 2 {
+			return Fail(pr, "merge", errors.New("boom"))
+		}
+		if pr.Number == 4 {
+			return Skip(pr, "merge", errors.New("CI checks not passing"))
+		}
+		return Done(pr, "merge", "")
+	}
+
+	var finished []Event
+	summary := Run(prs, fn, Options{Concurrency: 2, OnEvent: func(e Event) {
+		if e.Kind == EventFinished {
+			finished = append(finished, e)
+		}
+	}})
+
+	if maxInFlight > 2 {
+		t.Errorf("ran %d PRs at once, want at most 2", maxInFlight)
+	}
+	if len(order) != 3 || order[0] != 1 || order[1] != 3 || order[2] != 5 {
+		t.Errorf("org/app PRs ran in order %v, want [1 3 5]", order)
+	}
+	if summary.Done != 3 || summary.Skipped != 1 || summary.Failed != 1 {
+		t.Errorf("summary = %s, want 3 done, 1 skipped, 1 failed", summary)
+	}
+	for i, r := range summary.Results {
+		if r.PR.Number != prs[i].Number {
+			t.Errorf("Results[%d] is #%d, want #%d", i, r.PR.Number, prs[i].Number)
+		}
+	}
+	if len(finished) != len(prs) || finished[len(finished)-1].Completed != len(prs) || finished[0].Total != len(prs) {
+		t.Errorf("finished events = %+v", finished)
+	}
+}
+
+func TestRunNoPRs(t *testing.T) {
+	summary := Run(nil, func(pr types.PR) Result {
+		t.Error("fn called without PRs")
+		return Result{}
+	}, Options{})
+	if len(summary.Results) != 0 || summary.Done+summary.Skipped+summary.Failed != 0 {
+		t.Errorf("summary = %+v, want empty", summary)
+	}
+}
+
+func TestRunRetriesPending(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1},
+		{Repo: "org/app", Number: 2},
+		{Repo: "org/api", Number: 3},
+	}
+
+	var mu sync.Mutex
+	calls := make(map[int]int)
+	fn := func(pr types.PR) Result {
+		mu.Lock()
+		calls[pr.Number]++
+		n := calls[pr.Number]
+		mu.Unlock()
+
+		switch {
+		case pr.Number == 1 && n < 3:
+			// Turns green on the third poll; retried without the approval
+			r := Pending(pr, "merge", errors.New("CI checks pending"))
+			r.Retry = func(pr types.PR) Result {
+				mu.Lock()
+				calls[pr.Number]++
+				n := calls[pr.Number]
+				mu.Unlock()
+				if n < 3 {
+					return Pending(pr, "merge", errors.New("CI checks pending"))
+				}
+				return Done(pr, "merge", "")
+			}
+			return r
+		case pr.Number == 3:
+			return Pending(pr, "merge", errors.New("CI checks pending"))
+		}
+		return Done(pr, "merge", "")
+	}
+
+	var finishedOrder []int
+	pendingEvents := 0
+	summary := Run(prs, fn, Options{Wait: 50 * time.Millisecond, PollInterval: time.Millisecond, OnEvent: func(e Event) {
+		switch e.Kind {
+		case EventFinished:
+			finishedOrder = append(finishedOrder, e.PR.Number)
+		case EventPending:
+			pendingEvents++
+		}
+	}})
+
+	if summary.Done != 2 || summary.Skipped != 1 {
+		t.Fatalf("summary = %s, want 2 done, 1 skipped", summary)
+	}
+	if r := summary.Results[2]; r.Status != StatusSkipped || r.Err == nil || !strings.Contains(r.Err.Error(), "gave up after 50ms") {
+		t.Errorf("timed out result = %+v", r)
+	}
+	if calls[1] != 3 {
+		t.Errorf("#1 ran %d times, want 3", calls[1])
+	}
+	// #2 doesn't wait for #1 to turn green
+	if finishedOrder[0] != 2 {
+		t.Errorf("finished in order %v, want #2 first", finishedOrder)
+	}
+	if pendingEvents < 3 {
+		t.Errorf("got %d pending events, want at least 3", pendingEvents)
+	}
+}
+
+func TestRunSkipsPendingWithoutWait(t *testing.T) {
+	summary := Run([]types.PR{{Repo: "org/app", Number: 1}}, func(pr types.PR) Result {
+		return Pending(pr, "merge", errors.New("CI checks not passing (state: pending)"))
+	}, Options{})
+
+	r := summary.Results[0]
+	if r.Status != StatusSkipped || r.Err.Error() != "CI checks not passing (state: pending)" {
+		t.Errorf("result = %+v, want skipped with the pending reason", r)
+	}
+}
+
+func TestRunTrain(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1},
+		{Repo: "org/app", Number: 2},
+		{Repo: "org/app", Number: 3},
+		{Repo: "org/api", Number: 4},
+		{Repo: "org/api", Number: 5},
+	}
+
+	var mu sync.Mutex
+	calls := make(map[int]int)
+	var ran []int
+	fn := func(pr types.PR) Result {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[pr.Number]++
+		ran = append(ran, pr.Number)
+
+		switch {
+		case pr.Number == 1 && calls[1] < 3:
+			return Pending(pr, "merge", errors.New("waiting for a rebase"))
+		case pr.Number == 4:
+			return Fail(pr, "merge", errors.New("CI checks failed"))
+		}
+		return Done(pr, "merge", "")
+	}
+
+	summary := Run(prs, fn, Options{Train: true, Wait: time.Second, PollInterval: time.Millisecond})
+
+	if summary.Done != 3 || summary.Failed != 1 || summary.Skipped != 1 {
+		t.Fatalf("summary = %s, want 3 done, 1 skipped, 1 failed", summary)
+	}
+	if r := summary.Results[4]; r.Status != StatusSkipped || r.Err.Error() != "train stopped: #4 failed" {
+		t.Errorf("#5 result = %+v, want skipped by the stopped train", r)
+	}
+	if calls[5] != 0 {
+		t.Error("#5 ran after #4 stopped the train")
+	}
+
+	// #2 and #3 wait for #1 to merge
+	var app []int
+	for _, n := range ran {
+		if n <= 3 {
+			app = append(app, n)
+		}
+	}
+	if len(app) != 5 || app[2] != 1 || app[3] != 2 || app[4] != 3 {
+		t.Errorf("org/app ran %v, want [1 1 1 2 3]", app)
+	}
+}
+
+func TestRunTrainTimeout(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1},
+		{Repo: "org/app", Number: 2},
+	}
+
+	summary := Run(prs, func(pr types.PR) Result {
+		if pr.Number == 1 {
+			return Pending(pr, "merge", errors.New("waiting for a rebase"))
+		}
+		t.Error("#2 ran while #1 never merged")
+		return Done(pr, "merge", "")
+	}, Options{Train: true, Wait: 10 * time.Millisecond, PollInterval: time.Millisecond})
+
+	if r := summary.Results[1]; r.Status != StatusSkipped || r.Err.Error() != "train stopped: #1 timed out" {
+		t.Errorf("#2 result = %+v, want skipped by the timed out train", r)
+	}
+}
@@ -3,19 +3,57 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 
 	"github.com/cli/go-gh/v2"
 	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/cli/go-gh/v2/pkg/config"
+	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/httpcache"
 	"github.com/jackchuka/gh-dep/internal/types"
 )
 
-// GetClient returns a GitHub REST API client
+// responseCache enables the on-disk conditional-request cache for REST calls
+var responseCache = true
+
+// DisableResponseCache makes REST calls bypass the on-disk response cache
+func DisableResponseCache() {
+	responseCache = false
+}
+
+// GetClient returns a GitHub REST API client. Unless disabled, GET
+// responses are cached on disk and revalidated with ETags.
 func GetClient() (*api.RESTClient, error) {
-	return api.DefaultRESTClient()
+	if !responseCache || usesUnixSocket() {
+		return api.DefaultRESTClient()
+	}
+
+	dir, err := cache.Dir()
+	if err != nil {
+		return api.DefaultRESTClient()
+	}
+
+	return api.NewRESTClient(api.ClientOptions{
+		Transport: httpcache.New(filepath.Join(dir, "http"), nil),
+	})
+}
+
+// usesUnixSocket reports whether gh is configured to reach the API over a
+// unix socket, which go-gh only sets up when no custom transport is given
+func usesUnixSocket() bool {
+	cfg, err := config.Read(nil)
+	if err != nil {
+		return false
+	}
+	socket, _ := cfg.Get([]string{"http_unix_socket"})
+	return socket != ""
 }
 
 type SearchParams struct {
@@ -26,37 +64,106 @@ type SearchParams struct {
 	Limit           int
 	ReviewRequested string
 	Archived        bool
+	HeadPrefixes    []string // also discover PRs whose head branch starts with one of these, whoever opened them
+	DiscoverLabels  []string // also discover PRs carrying one of these labels, whoever opened them
+	ExcludeLabels   []string // skip PRs carrying any of these labels
+	ExcludeRepos    []string // skip repos matching these names or globs (OWNER/REPO, or REPO for any owner)
+	Topics          []string // only search repos tagged with one of these topics
+}
+
+// Record returns the parameters in the form stored alongside cached groups
+func (p SearchParams) Record() types.SearchRecord {
+	return types.SearchRecord{
+		Owner:           p.Owner,
+		Repos:           p.Repos,
+		Label:           p.Label,
+		Authors:         p.Authors,
+		Limit:           p.Limit,
+		ReviewRequested: p.ReviewRequested,
+		Archived:        p.Archived,
+		HeadPrefixes:    p.HeadPrefixes,
+		DiscoverLabels:  p.DiscoverLabels,
+		ExcludeLabels:   p.ExcludeLabels,
+		ExcludeRepos:    p.ExcludeRepos,
+		Topics:          p.Topics,
+	}
+}
+
+// ParamsFromRecord returns the search parameters stored with cached groups
+func ParamsFromRecord(r types.SearchRecord) SearchParams {
+	return SearchParams{
+		Owner:           r.Owner,
+		Repos:           r.Repos,
+		Label:           r.Label,
+		Authors:         r.Authors,
+		Limit:           r.Limit,
+		ReviewRequested: r.ReviewRequested,
+		Archived:        r.Archived,
+		HeadPrefixes:    r.HeadPrefixes,
+		DiscoverLabels:  r.DiscoverLabels,
+		ExcludeLabels:   r.ExcludeLabels,
+		ExcludeRepos:    r.ExcludeRepos,
+		Topics:          r.Topics,
+	}
+}
+
+// searchQuery narrows a single search run to one author, head branch or label
+type searchQuery struct {
+	author string
+	head   string
+	label  string
 }
 
 // SearchPRs searches for PRs based on the given parameters.
 // When multiple authors are specified, runs one search per author and merges results.
+// Head branch prefixes and discovery labels add one search each, merged into
+// the same results, so PRs opened by service accounts are found too.
+// Topics narrow the scope to the repos tagged with them, and excluded
+// repos are dropped from the results.
 func SearchPRs(params SearchParams) ([]types.PR, error) {
+	if len(params.Topics) > 0 {
+		repos, err := reposWithTopics(params)
+		if err != nil {
+			return nil, err
+		}
+		if len(repos) == 0 {
+			return nil, nil
+		}
+		params.Owner = ""
+		params.Repos = repos
+	}
+
 	authors := params.Authors
 	if len(authors) == 0 {
 		authors = []string{""}
 	}
 
-	var allPRs []types.PR
-	seen := make(map[string]bool)
-
+	var queries []searchQuery
 	for _, author := range authors {
-		prs, err := searchPRsForAuthor(params, author)
+		queries = append(queries, searchQuery{author: author})
+	}
+	for _, prefix := range params.HeadPrefixes {
+		queries = append(queries, searchQuery{head: prefix})
+	}
+	for _, label := range params.DiscoverLabels {
+		queries = append(queries, searchQuery{label: label})
+	}
+
+	results := make([][]types.PR, len(queries))
+	for i, q := range queries {
+		prs, err := searchPRsFor(params, q)
 		if err != nil {
 			return nil, err
 		}
-		for _, pr := range prs {
-			key := fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
-			if !seen[key] {
-				seen[key] = true
-				allPRs = append(allPRs, pr)
-			}
-		}
+		results[i] = prs
 	}
+	allPRs, headOnly := mergeResults(queries, results, params.ExcludeRepos)
 
-	// Fetch CI status concurrently with worker pool
+	// Fetch PR details and CI status concurrently with worker pool
 	const maxWorkers = 10
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, maxWorkers)
+	errs := make([]error, len(allPRs))
 
 	for i := range allPRs {
 		wg.Add(1)
@@ -65,46 +172,186 @@ func SearchPRs(params SearchParams) ([]types.PR, error) {
 			semaphore <- struct{}{}        // Acquire
 			defer func() { <-semaphore }() // Release
 
-			headSHA, err := GetPRHead(allPRs[idx].Repo, allPRs[idx].Number)
-			if err == nil {
-				allPRs[idx].HeadSHA = headSHA
-				ciStatus, err := GetCIStatus(allPRs[idx].Repo, headSHA)
-				if err == nil && ciStatus != nil {
-					allPRs[idx].CIStatus = ciStatus.State
-				}
+			details, err := GetPR(allPRs[idx].Repo, allPRs[idx].Number)
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+			allPRs[idx].HeadSHA = details.HeadSHA
+			allPRs[idx].HeadRef = details.HeadRef
+			ciStatus, err := GetCIStatus(allPRs[idx].Repo, details.HeadSHA)
+			if err == nil && ciStatus != nil {
+				allPRs[idx].CIStatus = ciStatus.State
 			}
 		}(i)
 	}
 
 	wg.Wait()
-	return allPRs, nil
+
+	// Without its head branch, a PR found by a head search can't be told
+	// apart from one that only mentions the prefix
+	for i, err := range errs {
+		if err != nil && headOnly[prKey(allPRs[i])] {
+			return nil, fmt.Errorf("failed to fetch the head branch of %s: %w", prKey(allPRs[i]), err)
+		}
+	}
+
+	return filterHeadOnly(allPRs, headOnly, params.HeadPrefixes), nil
 }
 
-func searchPRsForAuthor(params SearchParams, author string) ([]types.PR, error) {
-	args := []string{"search", "prs", "is:open"}
+// mergeResults merges the results of the queries in order, dropping PRs in
+// excluded repos and PRs already found. It also returns the PRs found only
+// through a head search: the search matches branch name words rather than
+// prefixes, so these are checked once their ref is known.
+func mergeResults(queries []searchQuery, results [][]types.PR, excludeRepos []string) ([]types.PR, map[string]bool) {
+	var merged []types.PR
+	seen := make(map[string]bool)
+	headOnly := make(map[string]bool)
 
-	if params.Owner != "" {
-		args = append(args, "--owner", params.Owner)
+	for i, q := range queries {
+		for _, pr := range results[i] {
+			if MatchRepo(pr.Repo, excludeRepos) {
+				continue
+			}
+			key := prKey(pr)
+			if q.head == "" {
+				delete(headOnly, key)
+			}
+			if !seen[key] {
+				seen[key] = true
+				if q.head != "" {
+					headOnly[key] = true
+				}
+				merged = append(merged, pr)
+			}
+		}
 	}
-	for _, repo := range params.Repos {
-		args = append(args, "--repo", repo)
+	return merged, headOnly
+}
+
+// filterHeadOnly drops the PRs found only through a head search whose head
+// branch doesn't start with one of the prefixes
+func filterHeadOnly(prs []types.PR, headOnly map[string]bool, prefixes []string) []types.PR {
+	if len(headOnly) == 0 {
+		return prs
 	}
 
-	if params.Label != "" {
-		args = append(args, "--label", params.Label)
+	filtered := prs[:0]
+	for _, pr := range prs {
+		if headOnly[prKey(pr)] && !hasAnyPrefix(pr.HeadRef, prefixes) {
+			continue
+		}
+		filtered = append(filtered, pr)
 	}
-	if author != "" {
-		args = append(args, "--author", author)
+	return filtered
+}
+
+// prKey identifies a PR across searches, e.g. "org/app#12"
+func prKey(pr types.PR) string {
+	return fmt.Sprintf("%s#%d", pr.Repo, pr.Number)
+}
+
+// hasAnyPrefix reports whether ref starts with one of the prefixes
+func hasAnyPrefix(ref string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
 	}
-	if params.ReviewRequested != "" {
-		args = append(args, "--review-requested", params.ReviewRequested)
+	return false
+}
+
+// MatchRepo reports whether repo matches one of the patterns.
+// Patterns without a slash match the repo name under any owner.
+func MatchRepo(repo string, patterns []string) bool {
+	repo = strings.ToLower(repo)
+	_, name, _ := strings.Cut(repo, "/")
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		target := repo
+		if !strings.Contains(pattern, "/") {
+			target = name
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
 	}
-	if !params.Archived {
-		args = append(args, fmt.Sprintf("--archived=%t", params.Archived))
+	return false
+}
+
+// validRepoName reports whether repo is a literal OWNER/REPO rather than a
+// name or glob
+func validRepoName(repo string) bool {
+	return strings.Count(repo, "/") == 1 && !strings.ContainsAny(repo, "*?[\\")
+}
+
+// reposWithTopics returns the repos in the search scope tagged with any of
+// the topics. The scope is the owner, or the owners of the listed repos
+// narrowed to those repos.
+func reposWithTopics(params SearchParams) ([]string, error) {
+	owners := []string{params.Owner}
+	if params.Owner == "" {
+		owners = nil
+		for _, repo := range params.Repos {
+			owner, _, _ := strings.Cut(repo, "/")
+			if !slices.Contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
 	}
-	args = append(args, "--json", "number,title,author,url,repository")
-	if params.Limit > 0 {
-		args = append(args, "--limit", fmt.Sprintf("%d", params.Limit))
+
+	var repos []string
+	for _, owner := range owners {
+		for _, topic := range params.Topics {
+			found, err := searchReposWithTopic(owner, topic, params.Archived)
+			if err != nil {
+				return nil, err
+			}
+			for _, repo := range found {
+				if len(params.Repos) > 0 && !slices.ContainsFunc(params.Repos, func(r string) bool { return strings.EqualFold(r, repo) }) {
+					continue
+				}
+				if !slices.Contains(repos, repo) {
+					repos = append(repos, repo)
+				}
+			}
+		}
+	}
+	return repos, nil
+}
+
+func searchReposWithTopic(owner, topic string, archived bool) ([]string, error) {
+	args := []string{"search", "repos", "--topic", topic, "--json", "fullName", "--limit", "1000"}
+	if owner != "" {
+		args = append(args, "--owner", owner)
+	}
+	if !archived {
+		args = append(args, "--archived=false")
+	}
+
+	stdOut, stdErr, err := gh.Exec(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repos with topic %q: %w\n%s", topic, err, stdErr.String())
+	}
+
+	var rawRepos []struct {
+		FullName string `json:"fullName"`
+	}
+	if err := parseJSON(stdOut.String(), &rawRepos); err != nil {
+		return nil, fmt.Errorf("failed to parse repo search results: %w", err)
+	}
+
+	repos := make([]string, len(rawRepos))
+	for i, raw := range rawRepos {
+		repos[i] = raw.FullName
+	}
+	return repos, nil
+}
+
+func searchPRsFor(params SearchParams, q searchQuery) ([]types.PR, error) {
+	stdOut, stdErr, err := gh.Exec(searchArgs(params, q)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search PRs: %w\n%s", err, stdErr.String())
 	}
 
 	var rawPRs []struct {
@@ -118,12 +365,6 @@ func searchPRsForAuthor(params SearchParams, author string) ([]types.PR, error)
 			NameWithOwner string `json:"nameWithOwner"`
 		} `json:"repository"`
 	}
-
-	stdOut, stdErr, err := gh.Exec(args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search PRs: %w\n%s", err, stdErr.String())
-	}
-
 	if err := parseJSON(stdOut.String(), &rawPRs); err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -142,17 +383,58 @@ func searchPRsForAuthor(params SearchParams, author string) ([]types.PR, error)
 	return prs, nil
 }
 
-// GroupPRs groups PRs by package@version
-func GroupPRs(prs []types.PR, customPatterns []string) map[string][]types.PR {
-	groups := make(map[string][]types.PR)
+// searchArgs builds the gh search prs arguments for one search run
+func searchArgs(params SearchParams, q searchQuery) []string {
+	args := []string{"search", "prs", "is:open"}
 
-	for _, pr := range prs {
-		update := parser.ParseTitle(pr.Title, customPatterns)
-		key := update.GroupKey()
-		groups[key] = append(groups[key], pr)
+	if params.Owner != "" {
+		args = append(args, "--owner", params.Owner)
+	}
+	for _, repo := range params.Repos {
+		args = append(args, "--repo", repo)
+	}
+
+	if params.Label != "" {
+		args = append(args, "--label", params.Label)
+	}
+	if q.label != "" && q.label != params.Label {
+		args = append(args, "--label", q.label)
+	}
+	if q.author != "" {
+		args = append(args, "--author", q.author)
+	}
+	if q.head != "" {
+		// The head qualifier matches whole words, so search for the prefix
+		// without its trailing separator
+		args = append(args, "--head", strings.TrimRight(q.head, "/-_"))
+	}
+	if params.ReviewRequested != "" {
+		args = append(args, "--review-requested", params.ReviewRequested)
+	}
+	if !params.Archived {
+		args = append(args, fmt.Sprintf("--archived=%t", params.Archived))
+	}
+	args = append(args, "--json", "number,title,author,url,repository")
+	if params.Limit > 0 {
+		args = append(args, "--limit", fmt.Sprintf("%d", params.Limit))
 	}
 
-	return groups
+	// Exclusions are negated qualifiers, which gh only accepts after --.
+	// Repo globs can't be expressed in a query and are filtered afterwards.
+	var exclusions []string
+	for _, label := range params.ExcludeLabels {
+		exclusions = append(exclusions, fmt.Sprintf("-label:%q", label))
+	}
+	for _, repo := range params.ExcludeRepos {
+		if validRepoName(repo) {
+			exclusions = append(exclusions, "-repo:"+repo)
+		}
+	}
+	if len(exclusions) > 0 {
+		args = append(append(args, "--"), exclusions...)
+	}
+
+	return args
 }
 
 // ApprovePR approves a pull request
@@ -203,6 +485,96 @@ func MergeViaPR(repo string, number int, method string) error {
 	return nil
 }
 
+// CommentOnPR adds a comment to a PR, e.g. a bot command
+func CommentOnPR(repo string, number int, body string) error {
+	client, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	bodyBytes, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d/comments", repo, number)
+	if err := client.Post(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to comment on PR #%d: %w", number, err)
+	}
+
+	return nil
+}
+
+// AddLabel adds a label to a PR
+func AddLabel(repo string, number int, label string) error {
+	client, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	bodyBytes, err := json.Marshal(map[string][]string{"labels": {label}})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d/labels", repo, number)
+	if err := client.Post(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to label PR #%d: %w", number, err)
+	}
+
+	return nil
+}
+
+// UpdateBranch merges the base branch into a PR's head branch. headSHA is
+// the head the update applies to; GitHub refuses it if the head has moved.
+func UpdateBranch(repo string, number int, headSHA string) error {
+	client, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	bodyBytes, err := json.Marshal(map[string]string{"expected_head_sha": headSHA})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("repos/%s/pulls/%d/update-branch", repo, number)
+	if err := client.Put(path, bytes.NewReader(bodyBytes), nil); err != nil {
+		return fmt.Errorf("failed to update the branch of PR #%d: %w", number, err)
+	}
+
+	return nil
+}
+
+// DeleteHeadBranch deletes the head branch of a merged PR. Branches of
+// forks, and branches already deleted (e.g. by the repo's auto-delete
+// setting), are left alone.
+func DeleteHeadBranch(repo string, number int) error {
+	details, err := GetPR(repo, number)
+	if err != nil {
+		return err
+	}
+	if details.HeadRef == "" || !strings.EqualFold(details.HeadRepo, repo) {
+		return nil
+	}
+
+	client, err := GetClient()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("repos/%s/git/refs/heads/%s", repo, details.HeadRef)
+	if err := client.Delete(path, nil); err != nil {
+		var httpErr *api.HTTPError
+		if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusUnprocessableEntity) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete branch %s: %w", details.HeadRef, err)
+	}
+
+	return nil
+}
+
 // CheckStatus represents CI status
 type CheckStatus struct {
 	State     string // success, pending, failure, error
@@ -227,25 +599,143 @@ type checkSuite struct {
 	Conclusion *string `json:"conclusion"`
 }
 
-// GetPRHead fetches the HEAD SHA for a PR (useful when SearchPRs doesn't return it)
-func GetPRHead(repo string, number int) (string, error) {
+// PRDetails holds the fields of a PR that aren't returned by search
+type PRDetails struct {
+	HeadSHA        string
+	HeadRef        string
+	HeadRepo       string // OWNER/REPO the head branch lives in; differs from the PR's repo for forks
+	State          string // open or closed
+	Merged         bool
+	MergeCommitSHA string // the commit the merge made on the base branch, once merged
+	Draft          bool
+	Mergeable      *bool  // nil while GitHub is still computing it
+	MergeableState string // clean, dirty, blocked, behind, unstable, unknown, ...
+}
+
+// GetPR fetches a PR's head, state and mergeability
+func GetPR(repo string, number int) (*PRDetails, error) {
 	client, err := GetClient()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var pr struct {
-		Head struct {
-			SHA string `json:"sha"`
+		State          string `json:"state"`
+		Merged         bool   `json:"merged"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		Draft          bool   `json:"draft"`
+		Mergeable      *bool  `json:"mergeable"`
+		MergeableState string `json:"mergeable_state"`
+		Head           struct {
+			SHA  string `json:"sha"`
+			Ref  string `json:"ref"`
+			Repo *struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
 		} `json:"head"`
 	}
 
 	path := fmt.Sprintf("repos/%s/pulls/%d", repo, number)
 	if err := client.Get(path, &pr); err != nil {
-		return "", fmt.Errorf("failed to get PR #%d: %w", number, err)
+		return nil, fmt.Errorf("failed to get PR #%d: %w", number, err)
 	}
 
-	return pr.Head.SHA, nil
+	var headRepo string
+	if pr.Head.Repo != nil {
+		headRepo = pr.Head.Repo.FullName
+	}
+
+	return &PRDetails{
+		HeadSHA:        pr.Head.SHA,
+		HeadRef:        pr.Head.Ref,
+		HeadRepo:       headRepo,
+		State:          pr.State,
+		Merged:         pr.Merged,
+		MergeCommitSHA: pr.MergeCommitSHA,
+		Draft:          pr.Draft,
+		Mergeable:      pr.Mergeable,
+		MergeableState: pr.MergeableState,
+	}, nil
+}
+
+// GetPRHead fetches the HEAD SHA for a PR (useful when SearchPRs doesn't return it)
+func GetPRHead(repo string, number int) (string, error) {
+	details, err := GetPR(repo, number)
+	if err != nil {
+		return "", err
+	}
+	return details.HeadSHA, nil
+}
+
+// MergeBlocker returns why a PR can't be merged as it is, or nil if it
+// can. status is the CI status of its head, nil when checks aren't
+// required; reviews reports whether missing reviews block it.
+func MergeBlocker(details *PRDetails, status *CheckStatus, reviews bool) error {
+	switch {
+	case details.Merged:
+		return nil
+	case details.State == "closed":
+		return errors.New("closed")
+	case details.Draft:
+		return errors.New("draft")
+	case details.Mergeable == nil || details.MergeableState == "unknown":
+		return errors.New("mergeability not computed yet")
+	case !*details.Mergeable || details.MergeableState == "dirty":
+		return errors.New("conflicts with the base branch")
+	case details.MergeableState == "behind":
+		return errors.New("behind the base branch")
+	case details.MergeableState == "blocked" && reviews:
+		return errors.New("blocked by branch protection (reviews or required checks)")
+	}
+
+	if status != nil && !status.AllPassed {
+		return fmt.Errorf("CI checks not passing (state: %s)", status.State)
+	}
+	return nil
+}
+
+// CountApprovals returns the number of reviewers whose latest review of a
+// PR approves it
+func CountApprovals(repo string, number int) (int, error) {
+	client, err := GetClient()
+	if err != nil {
+		return 0, err
+	}
+
+	var reviews []review
+	path := fmt.Sprintf("repos/%s/pulls/%d/reviews?per_page=100", repo, number)
+	if err := client.Get(path, &reviews); err != nil {
+		return 0, fmt.Errorf("failed to get reviews for PR #%d: %w", number, err)
+	}
+
+	return countApprovals(reviews), nil
+}
+
+type review struct {
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// countApprovals counts reviewers whose latest approving or blocking
+// review is an approval. Comments don't change a reviewer's verdict.
+func countApprovals(reviews []review) int {
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[r.User.Login] = r.State
+		}
+	}
+
+	count := 0
+	for _, state := range latest {
+		if state == "APPROVED" {
+			count++
+		}
+	}
+	return count
 }
 
 // GetCIStatus checks the CI status for a PR
@@ -1,6 +1,12 @@
 package github
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/types"
+)
 
 func TestDeriveCIState(t *testing.T) {
 	tests := []struct {
@@ -120,3 +126,174 @@ func TestDeriveCIState(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestCountApprovals(t *testing.T) {
+	r := func(login, state string) review {
+		var rv review
+		rv.User.Login = login
+		rv.State = state
+		return rv
+	}
+
+	tests := []struct {
+		name    string
+		reviews []review
+		want    int
+	}{
+		{name: "none", want: 0},
+		{name: "two reviewers", reviews: []review{r("alice", "APPROVED"), r("bob", "APPROVED")}, want: 2},
+		{name: "same reviewer twice", reviews: []review{r("alice", "APPROVED"), r("alice", "APPROVED")}, want: 1},
+		{name: "comment keeps approval", reviews: []review{r("alice", "APPROVED"), r("alice", "COMMENTED")}, want: 1},
+		{name: "changes requested after approval", reviews: []review{r("alice", "APPROVED"), r("alice", "CHANGES_REQUESTED")}, want: 0},
+		{name: "dismissed", reviews: []review{r("alice", "APPROVED"), r("alice", "DISMISSED"), r("bob", "APPROVED")}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countApprovals(tt.reviews); got != tt.want {
+				t.Errorf("countApprovals() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeBlocker(t *testing.T) {
+	yes, no := true, false
+	open := func(mergeable *bool, state string) *PRDetails {
+		return &PRDetails{State: "open", Mergeable: mergeable, MergeableState: state}
+	}
+	green := &CheckStatus{State: "success", AllPassed: true}
+	red := &CheckStatus{State: "failure"}
+
+	tests := []struct {
+		name    string
+		details *PRDetails
+		status  *CheckStatus
+		reviews bool
+		want    string
+	}{
+		{name: "clean", details: open(&yes, "clean"), status: green},
+		{name: "checks not required", details: open(&yes, "unstable")},
+		{name: "already merged", details: &PRDetails{State: "closed", Merged: true}},
+		{name: "closed", details: &PRDetails{State: "closed"}, want: "closed"},
+		{name: "draft", details: &PRDetails{State: "open", Draft: true}, want: "draft"},
+		{name: "computing", details: open(nil, ""), want: "mergeability not computed yet"},
+		{name: "conflicts", details: open(&no, "dirty"), want: "conflicts with the base branch"},
+		{name: "behind", details: open(&yes, "behind"), want: "behind the base branch"},
+		{name: "needs reviews", details: open(&yes, "blocked"), reviews: true, want: "blocked by branch protection (reviews or required checks)"},
+		{name: "reviews ignored", details: open(&yes, "blocked"), status: green},
+		{name: "CI failing", details: open(&yes, "unstable"), status: red, want: "CI checks not passing (state: failure)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MergeBlocker(tt.details, tt.status, tt.reviews)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("MergeBlocker() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRepo(t *testing.T) {
+	patterns := []string{"myorg/legacy-*", "sandbox", "Other/App"}
+
+	tests := []struct {
+		repo string
+		want bool
+	}{
+		{"myorg/legacy-api", true},
+		{"myorg/api", false},
+		{"myorg/sandbox", true},
+		{"other/sandbox", true},
+		{"other/app", true},
+		{"myorg/app", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchRepo(tt.repo, patterns); got != tt.want {
+			t.Errorf("MatchRepo(%q) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestSearchArgsExclusions(t *testing.T) {
+	params := SearchParams{
+		Owner:         "myorg",
+		ExcludeLabels: []string{"do-not-merge", "on hold"},
+		ExcludeRepos:  []string{"myorg/app", "myorg/legacy-*", "sandbox"},
+	}
+
+	got := strings.Join(searchArgs(params, searchQuery{author: "app/dependabot"}), " ")
+	want := `--archived=false --json number,title,author,url,repository -- -label:"do-not-merge" -label:"on hold" -repo:myorg/app`
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("searchArgs() = %q, want it to end with %q", got, want)
+	}
+
+	if got := searchArgs(SearchParams{Owner: "myorg"}, searchQuery{}); slices.Contains(got, "--") {
+		t.Errorf("searchArgs() = %q, want no exclusions", got)
+	}
+}
+
+func TestSearchArgsHead(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"renovate/", "renovate"},
+		{"deps-", "deps"},
+		{"pre-commit-ci-update-config", "pre-commit-ci-update-config"},
+	}
+
+	for _, tt := range tests {
+		args := searchArgs(SearchParams{Owner: "myorg"}, searchQuery{head: tt.prefix})
+		i := slices.Index(args, "--head")
+		if i < 0 || i+1 >= len(args) || args[i+1] != tt.want {
+			t.Errorf("searchArgs(head %q) = %q, want --head %s", tt.prefix, args, tt.want)
+		}
+		if slices.Contains(args, "--author") {
+			t.Errorf("searchArgs(head %q) = %q, want no --author", tt.prefix, args)
+		}
+	}
+}
+
+func TestMergeResults(t *testing.T) {
+	queries := []searchQuery{
+		{author: "app/dependabot"},
+		{head: "renovate/"},
+		{head: "deps/"},
+		{label: "dependencies"},
+	}
+	results := [][]types.PR{
+		{{Repo: "org/app", Number: 1}},
+		{{Repo: "org/app", Number: 1}, {Repo: "org/app", Number: 2}, {Repo: "org/app", Number: 3}},
+		{{Repo: "org/app", Number: 2}, {Repo: "org/sandbox", Number: 4}},
+		{{Repo: "org/app", Number: 3}},
+	}
+
+	prs, headOnly := mergeResults(queries, results, []string{"sandbox"})
+
+	var keys []string
+	for _, pr := range prs {
+		keys = append(keys, prKey(pr))
+	}
+	if want := []string{"org/app#1", "org/app#2", "org/app#3"}; !slices.Equal(keys, want) {
+		t.Errorf("merged = %v, want %v", keys, want)
+	}
+	// #1 and #3 were also found by other searches, so only #2 needs its
+	// branch checked
+	if len(headOnly) != 1 || !headOnly["org/app#2"] {
+		t.Errorf("headOnly = %v, want only org/app#2", headOnly)
+	}
+
+	prs[0].HeadRef = "dependabot/npm_and_yarn/lodash-4.17.21"
+	prs[1].HeadRef = "feature/renovate-docs"
+	filtered := filterHeadOnly(prs, headOnly, []string{"renovate/", "deps/"})
+	if len(filtered) != 2 || filtered[0].Number != 1 || filtered[1].Number != 3 {
+		t.Errorf("filtered = %+v, want #1 and #3", filtered)
+	}
+}
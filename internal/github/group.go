@@ -0,0 +1,183 @@
+package github
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// GroupBy selects the strategy used to group PRs
+type GroupBy string
+
+const (
+	GroupByPackageVersion GroupBy = "package-version" // lodash@4.17.21 (default)
+	GroupByPackage        GroupBy = "package"         // lodash, across versions
+	GroupByPackageMajor   GroupBy = "package-major"   // lodash@4
+	GroupByEcosystem      GroupBy = "ecosystem"       // npm
+	GroupByRepo           GroupBy = "repo"            // owner/app
+	GroupByUpdateType     GroupBy = "update-type"     // major, minor, patch, digest
+	GroupByGroupName      GroupBy = "group-name"      // Renovate's groupName, falling back to package@version
+)
+
+// GroupByValues lists the supported grouping strategies
+var GroupByValues = []GroupBy{
+	GroupByPackageVersion,
+	GroupByPackage,
+	GroupByPackageMajor,
+	GroupByEcosystem,
+	GroupByRepo,
+	GroupByUpdateType,
+	GroupByGroupName,
+}
+
+// ParseGroupBy validates a grouping strategy name. An empty name selects
+// the default package-version strategy.
+func ParseGroupBy(value string) (GroupBy, error) {
+	normalized := strings.TrimSpace(strings.ToLower(value))
+	if normalized == "" {
+		return GroupByPackageVersion, nil
+	}
+
+	for _, g := range GroupByValues {
+		if string(g) == normalized {
+			return g, nil
+		}
+	}
+
+	names := make([]string, len(GroupByValues))
+	for i, g := range GroupByValues {
+		names[i] = string(g)
+	}
+	return "", fmt.Errorf("invalid group strategy: %q (expected one of %s)", value, strings.Join(names, ", "))
+}
+
+// GroupOptions controls how PRs are grouped
+type GroupOptions struct {
+	Patterns    []string       // custom title patterns, tried before the built-in ones
+	Bots        *bots.Registry // bots whose title patterns apply to their own PRs
+	Strategy    GroupBy        // grouping strategy, package-version when empty
+	ByDirectory bool           // split groups by the directory the update applies to
+}
+
+// PatternsFor returns the custom patterns to try for a PR: the configured
+// patterns followed by the title patterns of the bot behind it, found by
+// author or head branch
+func (o GroupOptions) PatternsFor(pr types.PR) []string {
+	if o.Bots == nil {
+		return o.Patterns
+	}
+	b, ok := o.Bots.ForPR(pr.Author, pr.HeadRef)
+	if !ok || len(b.TitlePatterns) == 0 {
+		return o.Patterns
+	}
+	return append(slices.Clone(o.Patterns), b.TitlePatterns...)
+}
+
+// ParseUpdate parses a PR's title with the patterns that apply to it
+func (o GroupOptions) ParseUpdate(pr types.PR) parser.PackageUpdate {
+	return parser.ParseTitle(pr.Title, o.PatternsFor(pr))
+}
+
+// GroupPRs groups PRs using the configured strategy, optionally split by
+// directory. Each PR's Directory is filled in from its title.
+func GroupPRs(prs []types.PR, opts GroupOptions) map[string][]types.PR {
+	groups := make(map[string][]types.PR)
+
+	for _, pr := range prs {
+		update := opts.ParseUpdate(pr)
+		pr.Directory = update.Directory
+		key := groupKey(pr, update, opts)
+		groups[key] = append(groups[key], pr)
+	}
+
+	return groups
+}
+
+// GroupKey returns the key GroupPRs would put the PR under
+func GroupKey(pr types.PR, opts GroupOptions) string {
+	return groupKey(pr, opts.ParseUpdate(pr), opts)
+}
+
+// AnnotatePRs fills in fields parsed from each PR's title, such as Directory
+func AnnotatePRs(prs []types.PR, opts GroupOptions) {
+	for i := range prs {
+		prs[i].Directory = opts.ParseUpdate(prs[i]).Directory
+	}
+}
+
+func groupKey(pr types.PR, update parser.PackageUpdate, opts GroupOptions) string {
+	key := strategyKey(pr, update, opts.Strategy)
+	if opts.ByDirectory && update.Directory != "" {
+		key += ":" + update.Directory
+	}
+	return key
+}
+
+func strategyKey(pr types.PR, update parser.PackageUpdate, strategy GroupBy) string {
+	switch strategy {
+	case GroupByPackage:
+		return packageName(update)
+	case GroupByPackageMajor:
+		return packageName(update) + "@" + majorVersion(update.ToVersion)
+	case GroupByEcosystem:
+		if update.Ecosystem != "" {
+			return update.Ecosystem
+		}
+		if ecosystem := branchEcosystem(pr.HeadRef); ecosystem != "" {
+			return ecosystem
+		}
+		return "unknown"
+	case GroupByRepo:
+		return pr.Repo
+	case GroupByUpdateType:
+		return update.UpdateType()
+	case GroupByGroupName:
+		if name := parser.ParseGroupName(pr.Title); name != "" {
+			return name
+		}
+		return update.GroupKey()
+	default:
+		return update.GroupKey()
+	}
+}
+
+// branchEcosystem reads the ecosystem from a Dependabot head branch, e.g.
+// npm from dependabot/npm_and_yarn/lodash-4.17.21. Returns "" for others.
+func branchEcosystem(headRef string) string {
+	rest, ok := strings.CutPrefix(headRef, "dependabot/")
+	if !ok {
+		return ""
+	}
+	ecosystem, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ""
+	}
+	return parser.NormalizeEcosystem(ecosystem)
+}
+
+func packageName(update parser.PackageUpdate) string {
+	if update.Name != "" {
+		return update.Name
+	}
+	return update.Package
+}
+
+// majorVersion reduces a version to its major component; digests and
+// unparseable versions are kept as they are
+func majorVersion(version string) string {
+	v := parser.ParseVersion(version)
+	switch v.Kind {
+	case parser.VersionSemver, parser.VersionCalver, parser.VersionMajor:
+		if len(v.Parts) > 0 {
+			return strconv.Itoa(v.Parts[0])
+		}
+	case parser.VersionDigest:
+		return "digest"
+	}
+	return version
+}
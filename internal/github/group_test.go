@@ -0,0 +1,139 @@
+package github
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestGroupPRsByDirectory(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/mono", Number: 1, Title: "Bump axios from 1.6.0 to 1.7.3 in /services/api"},
+		{Repo: "org/mono", Number: 2, Title: "Bump axios from 1.6.0 to 1.7.3 in /services/web"},
+		{Repo: "org/app", Number: 3, Title: "Bump axios from 1.6.0 to 1.7.3"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     GroupOptions
+		wantKeys []string
+	}{
+		{
+			name:     "package@version",
+			opts:     GroupOptions{},
+			wantKeys: []string{"axios@1.7.3"},
+		},
+		{
+			name:     "package@version and directory",
+			opts:     GroupOptions{ByDirectory: true},
+			wantKeys: []string{"axios@1.7.3", "axios@1.7.3:/services/api", "axios@1.7.3:/services/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := GroupPRs(prs, tt.opts)
+
+			var keys []string
+			for k := range groups {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Fatalf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			for _, pr := range groups[tt.wantKeys[0]] {
+				if pr.Number == 1 && pr.Directory != "/services/api" {
+					t.Errorf("expected Directory to be filled in, got %q", pr.Directory)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupPRsStrategies(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21"},
+		{Repo: "org/api", Number: 2, Title: "Bump lodash from 4.17.19 to 4.18.0"},
+		{Repo: "org/app", Number: 3, Title: "Bump @types/node from 20.10.0 to 22.0.0"},
+		{Repo: "org/api", Number: 4, Title: "Update all non-major dependencies"},
+	}
+
+	tests := []struct {
+		strategy GroupBy
+		wantKeys []string
+	}{
+		{GroupByPackageVersion, []string{"@types/node@22.0.0", "lodash@4.17.21", "lodash@4.18.0", "unknown@unknown"}},
+		{GroupByPackage, []string{"@types/node", "lodash", "unknown"}},
+		{GroupByPackageMajor, []string{"@types/node@22", "lodash@4", "unknown@unknown"}},
+		{GroupByEcosystem, []string{"npm", "unknown"}},
+		{GroupByRepo, []string{"org/api", "org/app"}},
+		{GroupByUpdateType, []string{"major", "minor", "patch", "unknown"}},
+		{GroupByGroupName, []string{"@types/node@22.0.0", "all non-major dependencies", "lodash@4.17.21", "lodash@4.18.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			groups := GroupPRs(prs, GroupOptions{Strategy: tt.strategy})
+
+			var keys []string
+			for k := range groups {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Fatalf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestGroupByEcosystemFromBranch(t *testing.T) {
+	prs := []types.PR{
+		{Repo: "org/app", Number: 1, Title: "Bump lodash from 4.17.20 to 4.17.21", HeadRef: "dependabot/npm_and_yarn/lodash-4.17.21"},
+		{Repo: "org/api", Number: 2, Title: "Bump github.com/spf13/cobra from 1.8.0 to 1.8.1", HeadRef: "dependabot/go_modules/github.com/spf13/cobra-1.8.1"},
+		{Repo: "org/api", Number: 3, Title: "Bump actions/checkout from 3 to 4", HeadRef: "dependabot/github_actions/actions/checkout-4"},
+		{Repo: "org/web", Number: 4, Title: "Bump rails from 7.1.0 to 7.1.3", HeadRef: "dependabot/bundler/rails-7.1.3"},
+		{Repo: "org/web", Number: 5, Title: "Update dependency lodash to v4.17.21", HeadRef: "renovate/lodash-4.x"},
+	}
+
+	groups := GroupPRs(prs, GroupOptions{Strategy: GroupByEcosystem})
+
+	var keys []string
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	if want := []string{"bundler", "github-actions", "go", "npm", "unknown"}; !slices.Equal(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	if unknown := groups["unknown"]; len(unknown) != 1 || unknown[0].Number != 5 {
+		t.Errorf("unknown = %+v, want only the Renovate PR #5", unknown)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if got, err := ParseGroupBy(""); err != nil || got != GroupByPackageVersion {
+		t.Fatalf("ParseGroupBy(\"\") = %q, %v; want default", got, err)
+	}
+	if got, err := ParseGroupBy("Update-Type"); err != nil || got != GroupByUpdateType {
+		t.Fatalf("ParseGroupBy(\"Update-Type\") = %q, %v", got, err)
+	}
+	if _, err := ParseGroupBy("author"); err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+}
+
+func TestParseUpdateBotByBranch(t *testing.T) {
+	opts := GroupOptions{Bots: bots.Builtin()}
+	pr := types.PR{Author: "acme-ci", HeadRef: "snyk-fix-1234", Title: "[Snyk] Upgrade lodash from 4.17.20 to 4.17.21"}
+
+	if got := opts.ParseUpdate(pr).GroupKey(); got != "lodash@4.17.21" {
+		t.Errorf("ParseUpdate() key = %q, want the snyk pattern to apply", got)
+	}
+}
@@ -0,0 +1,264 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxEntrySize is the largest response body that is stored
+	DefaultMaxEntrySize = 1 << 20 // 1 MiB
+	// DefaultMaxSize is the total size the cache directory is pruned to
+	DefaultMaxSize = 50 << 20 // 50 MiB
+)
+
+// Transport is an http.RoundTripper that makes conditional requests. It
+// stores GET responses carrying an ETag or Last-Modified header on disk and
+// replays those validators as If-None-Match / If-Modified-Since; a 304 Not
+// Modified is answered from the stored body. GitHub doesn't count 304s
+// against the rate limit.
+type Transport struct {
+	Dir          string            // directory holding the cached responses
+	Base         http.RoundTripper // transport making the requests, http.DefaultTransport when nil
+	MaxEntrySize int64             // responses with larger bodies aren't stored
+	MaxSize      int64             // the oldest entries are removed once the cache grows past this
+}
+
+// New returns a Transport storing responses in dir with the default limits
+func New(dir string, base http.RoundTripper) *Transport {
+	return &Transport{
+		Dir:          dir,
+		Base:         base,
+		MaxEntrySize: DefaultMaxEntrySize,
+		MaxSize:      DefaultMaxSize,
+	}
+}
+
+// entry is a stored response
+type entry struct {
+	URL          string      `json:"url"`
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !cacheable(req) {
+		return t.base().RoundTrip(req)
+	}
+
+	path := t.path(req)
+	stored := t.load(path)
+
+	if stored != nil {
+		req = req.Clone(req.Context())
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && stored != nil {
+		_ = resp.Body.Close()
+		// Mark the entry as recently used so pruning keeps it
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return stored.response(req, resp.Header), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+	if resp.ContentLength > t.MaxEntrySize {
+		return resp, nil
+	}
+
+	// Read one byte past the limit to tell oversized bodies of unknown length apart
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.MaxEntrySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.MaxEntrySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The cache is best effort; a failed write only costs a full response next time
+	t.store(path, &entry{
+		URL:          req.URL.String(),
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header,
+		Body:         body,
+		ETag:         etag,
+		LastModified: lastModified,
+	})
+
+	return resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// cacheable reports whether a request may be answered from the cache.
+// Requests that already carry validators or ask for a range are passed
+// through untouched.
+func cacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == ""
+}
+
+// path returns the file an entry is stored in. The key covers the
+// credentials and Accept header so responses never leak across tokens or
+// media types.
+func (t *Transport) path(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Authorization"), req.Header.Get("Accept")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(t.Dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (t *Transport) load(path string) *entry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		_ = os.Remove(path)
+		return nil
+	}
+	return &e
+}
+
+func (t *Transport) store(path string, e *entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.Dir, 0755); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(t.Dir, "*.tmp")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), path) != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+
+	t.prune()
+}
+
+// prune removes the least recently used entries until the cache fits in
+// MaxSize
+func (t *Transport) prune() {
+	files, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return
+	}
+
+	type cached struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var entries []cached
+	var total int64
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, cached{filepath.Join(t.Dir, f.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if total <= t.MaxSize {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	for _, e := range entries {
+		if total <= t.MaxSize {
+			break
+		}
+		if os.Remove(e.path) == nil {
+			total -= e.size
+		}
+	}
+}
+
+// response rebuilds the stored response, taking the fresh headers of the
+// 304 (rate limit, date) over the stored ones
+func (e *entry) response(req *http.Request, fresh http.Header) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for name, values := range fresh {
+		header[name] = values
+	}
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	header.Set("X-Gh-Dep-Cache", "hit")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
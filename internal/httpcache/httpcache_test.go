@@ -0,0 +1,122 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTransportRevalidatesWithETag(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, `{"state":"open"}`)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: New(t.TempDir(), nil)}
+	for i := range 3 {
+		resp, err := client.Get(server.URL + "/repos/org/app/pulls/1")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || string(body) != `{"state":"open"}` {
+			t.Fatalf("request %d = %d %q", i, resp.StatusCode, body)
+		}
+	}
+
+	if full != 1 || notModified != 2 {
+		t.Errorf("full = %d, not modified = %d; want 1 and 2", full, notModified)
+	}
+}
+
+func TestTransportSkipsUncacheableResponses(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("unexpected conditional request to %s", r.URL.Path)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Path == "/large" {
+			_, _ = io.WriteString(w, strings.Repeat("x", 64))
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := New(dir, nil)
+	transport.MaxEntrySize = 16
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL + "/large")
+		if err != nil {
+			t.Fatalf("GET /large failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if len(body) != 64 {
+			t.Fatalf("GET /large returned %d bytes, want 64", len(body))
+		}
+
+		resp, err = client.Post(server.URL+"/merge", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("POST /merge failed: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	if requests != 4 {
+		t.Errorf("requests = %d, want 4", requests)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("expected nothing cached, found %d files", len(files))
+	}
+}
+
+func TestTransportPrunesToMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		_, _ = io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := New(dir, nil)
+	transport.MaxSize = 1000
+	client := &http.Client{Transport: transport}
+
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e", "/f"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	var total int64
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		info, _ := f.Info()
+		total += info.Size()
+	}
+	if total > transport.MaxSize || len(files) == 0 {
+		t.Errorf("cache holds %d bytes in %d files, want at most %d", total, len(files), transport.MaxSize)
+	}
+}
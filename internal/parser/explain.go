@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pattern sources reported by Explain
+const (
+	SourceCustom  = "custom"
+	SourceBuiltIn = "built-in"
+)
+
+// PatternResult describes how a single pattern fared against a title
+type PatternResult struct {
+	Source  string            // SourceCustom or SourceBuiltIn
+	Pattern string            // the regex as written
+	Err     error             // why the pattern is invalid, for custom patterns only
+	Matched bool              // whether the pattern produced a package and version
+	Fields  map[string]string // captured fields, when matched
+}
+
+// Explain runs every pattern ParseTitle would try against the title, in the
+// same order (custom, then built-in), and reports each outcome. Unlike
+// ParseTitle it doesn't stop at the first match.
+func Explain(title string, customPatterns []string) []PatternResult {
+	var results []PatternResult
+
+	for _, patternStr := range customPatterns {
+		result := PatternResult{Source: SourceCustom, Pattern: patternStr}
+		if err := ValidatePattern(patternStr); err != nil {
+			// A pattern with the wrong groups never matches; say why
+			result.Err = err
+		} else {
+			result.Matched, result.Fields = explainMatch(regexp.MustCompile(patternStr), title)
+		}
+		results = append(results, result)
+	}
+
+	for _, pattern := range patterns {
+		result := PatternResult{Source: SourceBuiltIn, Pattern: pattern.String()}
+		result.Matched, result.Fields = explainMatch(pattern, title)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func explainMatch(re *regexp.Regexp, title string) (bool, map[string]string) {
+	update, ok := match(re, title)
+	if !ok {
+		return false, nil
+	}
+
+	update = update.normalize()
+	fields := map[string]string{
+		FieldPackage: update.Package,
+		FieldTo:      update.ToVersion,
+	}
+	if update.FromVersion != "" {
+		fields[FieldFrom] = update.FromVersion
+	}
+	if update.Ecosystem != "" {
+		fields[FieldEcosystem] = update.Ecosystem
+	}
+	if update.Directory != "" {
+		fields[FieldDirectory] = update.Directory
+	}
+	return true, fields
+}
+
+var (
+	versionToken   = regexp.MustCompile("^`?" + `v?\d[\w.+-]*` + "`?$")
+	connectorWords = []string{"to", "->", "→", "=>"}
+)
+
+// SuggestPattern proposes a custom pattern with named groups for a title the
+// built-in patterns can't parse. The last version-like word becomes the
+// target version, the word before it (skipping "to", "->") the package, and
+// an earlier version-like word the source version. Up to two words before
+// the package are kept literally to anchor the match. Returns "" when the
+// title contains no version.
+func SuggestPattern(title string) string {
+	words := strings.Fields(title)
+
+	toIdx := -1
+	for i := len(words) - 1; i >= 0; i-- {
+		if versionToken.MatchString(words[i]) {
+			toIdx = i
+			break
+		}
+	}
+	if toIdx <= 0 {
+		return ""
+	}
+
+	pkgIdx := toIdx - 1
+	for pkgIdx >= 0 && isConnector(words[pkgIdx]) {
+		pkgIdx--
+	}
+
+	fromIdx := -1
+	for pkgIdx >= 0 && versionToken.MatchString(words[pkgIdx]) {
+		// "<pkg> 1.0.0 -> 2.0.0": the word before the target is the source version
+		fromIdx = pkgIdx
+		pkgIdx--
+		for pkgIdx >= 0 && (isConnector(words[pkgIdx]) || strings.EqualFold(words[pkgIdx], "from")) {
+			pkgIdx--
+		}
+	}
+	if pkgIdx < 0 {
+		return ""
+	}
+
+	var parts []string
+	for i := max(pkgIdx-2, 0); i <= toIdx; i++ {
+		switch i {
+		case pkgIdx:
+			parts = append(parts, `(?P<package>[^\s]+)`)
+		case fromIdx:
+			parts = append(parts, `v?(?P<from>[^\s]+)`)
+		case toIdx:
+			parts = append(parts, `v?(?P<to>[^\s]+)`)
+		default:
+			parts = append(parts, regexp.QuoteMeta(words[i]))
+		}
+	}
+
+	return `(?i)` + strings.Join(parts, `\s+`)
+}
+
+func isConnector(word string) bool {
+	for _, c := range connectorWords {
+		if strings.EqualFold(word, c) {
+			return true
+		}
+	}
+	return false
+}
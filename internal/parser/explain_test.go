@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	custom := []string{`upgrade (?P<package>\S+ to (?P<to>\S+)`, `deps: (?P<package>\S+) -> (?P<to>\S+)`, `deps: (\S+) -> \S+`}
+	results := Explain("deps: webpack -> 5.89.0", custom)
+
+	if len(results) != len(custom)+len(patterns) {
+		t.Fatalf("expected %d results, got %d", len(custom)+len(patterns), len(results))
+	}
+	if results[0].Source != SourceCustom || results[0].Err == nil {
+		t.Errorf("expected first custom pattern to fail to compile, got %+v", results[0])
+	}
+	if !results[1].Matched || results[1].Fields[FieldPackage] != "webpack" || results[1].Fields[FieldTo] != "5.89.0" {
+		t.Errorf("expected second custom pattern to match, got %+v", results[1])
+	}
+	if results[2].Matched || results[2].Err == nil || !strings.Contains(results[2].Err.Error(), "exactly 2 capture groups") {
+		t.Errorf("expected the pattern with one group to be invalid, got %+v", results[2])
+	}
+	for _, r := range results[3:] {
+		if r.Source != SourceBuiltIn {
+			t.Errorf("expected built-in source, got %q", r.Source)
+		}
+	}
+}
+
+func TestSuggestPattern(t *testing.T) {
+	tests := []struct {
+		title    string
+		wantPkg  string
+		wantTo   string
+		wantFrom string
+	}{
+		{"deps: upgrade webpack → 5.89.0", "webpack", "5.89.0", ""},
+		{"[security] lodash 4.17.20 -> 4.17.21", "lodash", "4.17.21", "4.17.20"},
+		{"Pin actions/checkout v4", "actions/checkout", "4", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			suggestion := SuggestPattern(tt.title)
+			if err := ValidatePattern(suggestion); err != nil {
+				t.Fatalf("suggested pattern is invalid: %v", err)
+			}
+			got := ParseTitle(tt.title, []string{suggestion})
+			if got.Package != tt.wantPkg || got.ToVersion != tt.wantTo || got.FromVersion != tt.wantFrom {
+				t.Errorf("suggestion %q parsed %+v, want package=%q to=%q from=%q", suggestion, got, tt.wantPkg, tt.wantTo, tt.wantFrom)
+			}
+		})
+	}
+
+	if got := SuggestPattern("Some random PR title"); got != "" {
+		t.Errorf("expected no suggestion without a version, got %q", got)
+	}
+}
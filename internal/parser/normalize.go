@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Ecosystems recognized by package name normalization
+const (
+	EcosystemNPM           = "npm"
+	EcosystemGo            = "go"
+	EcosystemPython        = "pip"
+	EcosystemGitHubActions = "github-actions"
+	EcosystemDocker        = "docker"
+)
+
+// ecosystemAliases maps names used by Dependabot branches, Renovate managers
+// and custom patterns onto the ecosystems above
+var ecosystemAliases = map[string]string{
+	"npm":            EcosystemNPM,
+	"npm_and_yarn":   EcosystemNPM,
+	"yarn":           EcosystemNPM,
+	"pnpm":           EcosystemNPM,
+	"node":           EcosystemNPM,
+	"go":             EcosystemGo,
+	"gomod":          EcosystemGo,
+	"go_modules":     EcosystemGo,
+	"golang":         EcosystemGo,
+	"pip":            EcosystemPython,
+	"pipenv":         EcosystemPython,
+	"poetry":         EcosystemPython,
+	"pypi":           EcosystemPython,
+	"python":         EcosystemPython,
+	"uv":             EcosystemPython,
+	"github-actions": EcosystemGitHubActions,
+	"github_actions": EcosystemGitHubActions,
+	"actions":        EcosystemGitHubActions,
+	"docker":         EcosystemDocker,
+	"dockerfile":     EcosystemDocker,
+}
+
+var (
+	goMajorSuffix     = regexp.MustCompile(`/v[2-9]\d*$`)
+	gopkgMajorSuffix  = regexp.MustCompile(`^(gopkg\.in/.+)\.v\d+$`)
+	pythonSeparators  = regexp.MustCompile(`[-_.]+`)
+	trailingDirectory = regexp.MustCompile(`\s+in\s+/\S*$`)
+)
+
+// NormalizeEcosystem maps an ecosystem name onto its canonical form.
+// Unrecognized names are returned lowercased.
+func NormalizeEcosystem(ecosystem string) string {
+	e := strings.ToLower(strings.TrimSpace(ecosystem))
+	if canonical, ok := ecosystemAliases[e]; ok {
+		return canonical
+	}
+	return e
+}
+
+// imageRegistries are the hosts of container registries, whose image names
+// look like Go module paths (ghcr.io/org/image)
+var imageRegistries = []string{
+	"docker.io", "ghcr.io", "quay.io", "gcr.io", "registry.k8s.io",
+	"mcr.microsoft.com", "public.ecr.aws", "registry.gitlab.com",
+}
+
+// imageRegistrySuffixes match the per-project or per-region registry hosts,
+// e.g. us-docker.pkg.dev or 123456789012.dkr.ecr.us-east-1.amazonaws.com
+var imageRegistrySuffixes = []string{".gcr.io", ".pkg.dev", ".azurecr.io", ".amazonaws.com"}
+
+// DetectEcosystem guesses the ecosystem from the shape of a package name.
+// Returns "" when the name doesn't give it away.
+func DetectEcosystem(pkg string) string {
+	first, rest, nested := strings.Cut(pkg, "/")
+
+	switch {
+	case strings.HasPrefix(pkg, "@"):
+		return EcosystemNPM
+	case nested && first == "actions":
+		return EcosystemGitHubActions
+	case nested && isImageRegistry(first):
+		return EcosystemDocker
+	case nested && strings.Contains(rest, "@") && !strings.Contains(first, "."):
+		// Workflow steps pin actions as owner/repo@ref
+		return EcosystemGitHubActions
+	case nested && strings.Contains(first, ".") && !strings.ContainsAny(pkg, ":@"):
+		// Module paths start with a host: github.com/..., golang.org/x/..., k8s.io/...
+		return EcosystemGo
+	default:
+		return ""
+	}
+}
+
+// isImageRegistry reports whether host is a container registry
+func isImageRegistry(host string) bool {
+	host = strings.ToLower(host)
+	if slices.Contains(imageRegistries, host) {
+		return true
+	}
+	for _, suffix := range imageRegistrySuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePackage returns the name used to group equivalent packages:
+//   - go: major version suffixes are dropped (github.com/foo/bar/v2 -> github.com/foo/bar)
+//   - npm: scopes missing their "@" are restored (types/node -> @types/node)
+//   - pip: names are lowercased and separators collapsed per PEP 503 (Django_Rest -> django-rest)
+//   - others: names are lowercased, and the well-known @types scope is restored
+//
+// A trailing "in /directory" captured along with the name is always removed.
+func NormalizePackage(pkg, ecosystem string) string {
+	name := trailingDirectory.ReplaceAllString(strings.TrimSpace(pkg), "")
+
+	switch NormalizeEcosystem(ecosystem) {
+	case EcosystemGo:
+		name = goMajorSuffix.ReplaceAllString(name, "")
+		return gopkgMajorSuffix.ReplaceAllString(name, "$1")
+	case EcosystemNPM:
+		name = strings.ToLower(name)
+		if strings.Count(name, "/") == 1 && !strings.HasPrefix(name, "@") {
+			name = "@" + name
+		}
+		return name
+	case EcosystemPython:
+		return pythonSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	default:
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "types/") {
+			name = "@" + name
+		}
+		return name
+	}
+}
+
+// normalize fills in the ecosystem when it can be detected and sets the
+// normalized name used for grouping, keeping Package as captured
+func (u PackageUpdate) normalize() PackageUpdate {
+	if u.Ecosystem != "" {
+		u.Ecosystem = NormalizeEcosystem(u.Ecosystem)
+	} else {
+		u.Ecosystem = DetectEcosystem(u.Package)
+	}
+	u.Name = NormalizePackage(u.Package, u.Ecosystem)
+	return u
+}
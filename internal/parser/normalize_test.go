@@ -0,0 +1,99 @@
+package parser
+
+import "testing"
+
+func TestNormalizePackage(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkg       string
+		ecosystem string
+		want      string
+	}{
+		{"go major suffix", "github.com/foo/bar/v2", EcosystemGo, "github.com/foo/bar"},
+		{"go without suffix", "github.com/foo/bar", EcosystemGo, "github.com/foo/bar"},
+		{"go keeps v1 path segment", "github.com/foo/v1", EcosystemGo, "github.com/foo/v1"},
+		{"gopkg.in major", "gopkg.in/yaml.v3", EcosystemGo, "gopkg.in/yaml"},
+		{"npm scope restored", "types/node", EcosystemNPM, "@types/node"},
+		{"npm scoped unchanged", "@types/node", EcosystemNPM, "@types/node"},
+		{"npm alias", "babel/core", "npm_and_yarn", "@babel/core"},
+		{"python case", "Django", EcosystemPython, "django"},
+		{"python separators", "Flask_SQLAlchemy", "pypi", "flask-sqlalchemy"},
+		{"unknown lowercased", "Django", "", "django"},
+		{"unknown types scope", "types/node", "", "@types/node"},
+		{"trailing directory", "axios in /frontend", "", "axios"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizePackage(tt.pkg, tt.ecosystem); got != tt.want {
+				t.Errorf("NormalizePackage(%q, %q) = %q, want %q", tt.pkg, tt.ecosystem, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectEcosystem(t *testing.T) {
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"@types/node", EcosystemNPM},
+		{"github.com/foo/bar/v2", EcosystemGo},
+		{"golang.org/x/net", EcosystemGo},
+		{"k8s.io/client-go", EcosystemGo},
+		{"ghcr.io/org/image", EcosystemDocker},
+		{"docker.io/library/node", EcosystemDocker},
+		{"us-docker.pkg.dev/project/repo/image", EcosystemDocker},
+		{"actions/checkout", EcosystemGitHubActions},
+		{"docker/build-push-action@v5", EcosystemGitHubActions},
+		{"github/codeql-action/init@v3", EcosystemGitHubActions},
+		{"types/node", ""},
+		{"lodash", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			if got := DetectEcosystem(tt.pkg); got != tt.want {
+				t.Errorf("DetectEcosystem(%q) = %q, want %q", tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTitleKeepsImageMajorSuffix(t *testing.T) {
+	a := ParseTitle("Bump ghcr.io/org/image/v2 from 1.0.0 to 2.0.0", nil)
+	b := ParseTitle("Bump ghcr.io/org/image from 1.0.0 to 2.0.0", nil)
+	if a.GroupKey() == b.GroupKey() {
+		t.Errorf("GroupKey() = %q for both images, want the /v2 image kept apart", a.GroupKey())
+	}
+}
+
+func TestParseTitleNormalizesGroupKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Bump github.com/foo/bar from 1.0.0 to 2.0.0", "Bump github.com/foo/bar/v2 from 2.0.0-rc.1 to 2.0.0"},
+		{"Update dependency @types/node to v20.10.0", "Update dependency types/node to v20.10.0"},
+		{"Bump Django from 4.2.0 to 5.0.1", "Bump django from 4.2.0 to 5.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a, func(t *testing.T) {
+			a := ParseTitle(tt.a, nil)
+			b := ParseTitle(tt.b, nil)
+			if a.GroupKey() != b.GroupKey() {
+				t.Errorf("GroupKey mismatch: %q vs %q", a.GroupKey(), b.GroupKey())
+			}
+		})
+	}
+}
+
+func TestParseTitleKeepsOriginalPackage(t *testing.T) {
+	got := ParseTitle("Bump Django from 4.2.0 to 5.0.1", nil)
+	if got.Package != "Django" {
+		t.Errorf("Package = %q, want %q", got.Package, "Django")
+	}
+	if got.Name != "django" {
+		t.Errorf("Name = %q, want %q", got.Name, "django")
+	}
+}
@@ -1,15 +1,43 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 )
 
 // PackageUpdate represents a parsed dependency update
 type PackageUpdate struct {
-	Package   string
-	ToVersion string
+	Package     string // package name as it appears in the title, for display
+	Name        string // normalized package name, for grouping
+	FromVersion string
+	ToVersion   string
+	Ecosystem   string
+	Directory   string
 }
 
+// Named capture groups recognized in title patterns
+const (
+	FieldPackage   = "package"
+	FieldTo        = "to"
+	FieldFrom      = "from"
+	FieldEcosystem = "ecosystem"
+	FieldDirectory = "directory"
+)
+
+var knownFields = []string{FieldPackage, FieldTo, FieldFrom, FieldEcosystem, FieldDirectory}
+
+// version matches the version tokens bots put in titles: dotted versions,
+// calver, major-only tags (v4, 20-alpine) and commit or image digests,
+// optionally wrapped in backticks. Trailing dots are left out, so a version
+// ending a sentence doesn't take the period.
+const version = "`?" + `(?:v?\d(?:[\w.+-]*[\w+-])?|(?:sha256:)?[0-9a-f]{7,64})` + "`?"
+
+// directory matches the optional " in /path" suffix Dependabot adds for
+// updates outside the repository root
+const directory = `(?:\s+in\s+(?P<directory>/[^\s]*))?`
+
 // Patterns for matching PR titles
 var patterns = []*regexp.Regexp{
 	// Pattern 1: "bump/update PACKAGE from X to VERSION"
@@ -17,17 +45,26 @@ var patterns = []*regexp.Regexp{
 	// Examples:
 	// - "Bump lodash from 4.17.15 to 4.17.21"
 	// - "Update dependency typescript from 4.5.2 to 5.6.0"
-	// - "Bump package-name from 1.2.3 to 2.0.0"
-	regexp.MustCompile(`(?i)(?:bump|update)[:\s]+([^\s]+)\s+from\s+[^\s]+\s+to\s+v?(\d+\.\d+(?:\.\d+)?)`),
+	// - "Bump actions/checkout from 8e5e7e5 to 11bd719"
+	// - "Bump golang from `1a2b3c4` to `5d6e7f8`"
+	// - "Bump axios from 1.6.0 to 1.7.3 in /services/api"
+	// The from version is taken as is, since it may be a range ("^1.0.0")
+	regexp.MustCompile(`(?i)(?:bump|update)[:\s]+(?P<package>[^\s]+)\s+from\s+(?P<from>[^\s]+)\s+to\s+(?P<to>` + version + `)` + directory),
+
+	// Pattern 2: Renovate "update PACKAGE KIND to VERSION"
+	// Matches: "Update actions/checkout action to v4", "Update node Docker tag to v20",
+	// "update docker/build-push-action digest to 4a13e50", "Update eslint monorepo to v9"
+	regexp.MustCompile(`(?i)update\s+(?:dependency\s+)?(?P<package>[^\s]+)\s+(?:action|digest|docker\s+tag|docker\s+digest|image|orb|module|package|monorepo)\s+to\s+(?P<to>` + version + `)` + directory),
 
-	// Pattern 2: "[Uu]pdate PACKAGE to VERSION"
+	// Pattern 3: "[Uu]pdate PACKAGE to VERSION"
 	// Matches: "Update typescript to 5.6.0", "update dependency eslint to 8.57.0"
-	regexp.MustCompile(`(?i)update\s+(?:dependency\s+)?([^\s]+)\s+to\s+v?(\d+\.\d+(?:\.\d+)?)`),
+	regexp.MustCompile(`(?i)update\s+(?:dependency\s+)?(?P<package>[^\s]+)\s+to\s+(?P<to>` + version + `)` + directory),
 
-	// Pattern 3: Catch-all semver pattern
+	// Pattern 4: Catch-all version pattern
 	// Extracts package name and version from any title with "X to Y" format
-	// This is very permissive - just finds the last word before "to" and a semver after
-	regexp.MustCompile(`(?i)([^\s:]+)\s+to\s+v?(\d+\.\d+(?:\.\d+)?)`),
+	// This is very permissive - just finds the last word before "to" and a
+	// dotted version or v-prefixed major tag after
+	regexp.MustCompile(`(?i)(?P<package>[^\s:]+)\s+to\s+(?P<to>v?\d+(?:\.\d+)+[\w.+-]*|v\d+)\b`),
 }
 
 // ParseTitle attempts to extract package and version from a PR title
@@ -36,33 +73,170 @@ var patterns = []*regexp.Regexp{
 func ParseTitle(title string, customPatterns []string) PackageUpdate {
 	for _, patternStr := range customPatterns {
 		if re, err := regexp.Compile(patternStr); err == nil {
-			matches := re.FindStringSubmatch(title)
-			if len(matches) == 3 {
-				return PackageUpdate{
-					Package:   matches[1],
-					ToVersion: matches[2],
-				}
+			if update, ok := match(re, title); ok {
+				return update.normalize()
 			}
 		}
 	}
 
 	for _, pattern := range patterns {
-		matches := pattern.FindStringSubmatch(title)
-		if len(matches) == 3 {
-			return PackageUpdate{
-				Package:   matches[1],
-				ToVersion: matches[2],
-			}
+		if update, ok := match(pattern, title); ok {
+			return update.normalize()
 		}
 	}
 
 	return PackageUpdate{
 		Package:   "unknown",
+		Name:      "unknown",
 		ToVersion: "unknown",
 	}
 }
 
-// GroupKey returns the group key for this update
+// ValidatePattern checks that a custom pattern compiles and captures at least
+// a package and a target version, either through the named groups
+// (?P<package>...) and (?P<to>...) or through exactly two positional groups.
+func ValidatePattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+
+	if !hasNamedGroups(re) {
+		if re.NumSubexp() != 2 {
+			return fmt.Errorf("pattern %q must have named groups (package, to) or exactly 2 capture groups, found %d", pattern, re.NumSubexp())
+		}
+		return nil
+	}
+
+	var names []string
+	for _, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(knownFields, name) {
+			return fmt.Errorf("pattern %q has unknown named group %q (expected one of %s)", pattern, name, strings.Join(knownFields, ", "))
+		}
+		names = append(names, name)
+	}
+
+	for _, required := range []string{FieldPackage, FieldTo} {
+		if !slices.Contains(names, required) {
+			return fmt.Errorf("pattern %q is missing the named group %q", pattern, required)
+		}
+	}
+
+	return nil
+}
+
+// GroupKey returns the group key for this update, using the normalized
+// package name when available
 func (u PackageUpdate) GroupKey() string {
-	return u.Package + "@" + u.ToVersion
+	name := u.Name
+	if name == "" {
+		name = u.Package
+	}
+	return name + "@" + u.ToVersion
+}
+
+// Unknown reports whether the title couldn't be parsed by any pattern
+func (u PackageUpdate) Unknown() bool {
+	return u.Package == "unknown" && u.ToVersion == "unknown"
+}
+
+// match applies a single pattern to a title. Patterns with named groups are
+// read by name so fields may appear in any order; patterns without named
+// groups fall back to the positional (package, version) layout.
+func match(re *regexp.Regexp, title string) (PackageUpdate, bool) {
+	matches := re.FindStringSubmatch(title)
+	if matches == nil {
+		return PackageUpdate{}, false
+	}
+
+	if !hasNamedGroups(re) {
+		if len(matches) != 3 {
+			return PackageUpdate{}, false
+		}
+		return PackageUpdate{
+			Package:   matches[1],
+			ToVersion: NormalizeVersion(matches[2]),
+		}, true
+	}
+
+	var update PackageUpdate
+	for i, name := range re.SubexpNames() {
+		switch name {
+		case FieldPackage:
+			update.Package = matches[i]
+		case FieldTo:
+			update.ToVersion = NormalizeVersion(matches[i])
+		case FieldFrom:
+			update.FromVersion = NormalizeVersion(matches[i])
+		case FieldEcosystem:
+			update.Ecosystem = matches[i]
+		case FieldDirectory:
+			update.Directory = normalizeDirectory(matches[i])
+		}
+	}
+
+	if update.Package == "" || update.ToVersion == "" {
+		return PackageUpdate{}, false
+	}
+	return update, true
+}
+
+func hasNamedGroups(re *regexp.Regexp) bool {
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDirectory drops trailing slashes and treats the repository root as
+// no directory, so "/" and "" group together
+func normalizeDirectory(dir string) string {
+	dir = strings.TrimRight(strings.TrimSpace(dir), "/")
+	if dir == "" {
+		return ""
+	}
+	if !strings.HasPrefix(dir, "/") {
+		dir = "/" + dir
+	}
+	return dir
+}
+
+var (
+	// groupTitlePattern extracts the subject of a Renovate "update ..." title,
+	// dropping any conventional commit prefix, target version and trailing
+	// "(major)"-style annotation
+	groupTitlePattern = regexp.MustCompile(`(?i)^(?:[\w-]+(?:\([^)]*\))?!?:\s*)?update\s+(.+?)(?:\s+to\s+\S+)?(?:\s+\([^)]*\))?$`)
+
+	// singleDependencySubject matches subjects that name one dependency
+	// rather than a group, e.g. "dependency eslint" or "actions/checkout action"
+	singleDependencySubject = regexp.MustCompile(`(?i)^(?:dependency\s+\S+|\S+(?:\s+(?:action|digest|docker\s+tag|docker\s+digest|image|orb|module|package))?)$`)
+
+	lockFileMaintenance = regexp.MustCompile(`(?i)^(?:[\w-]+(?:\([^)]*\))?!?:\s*)?lock\s+file\s+maintenance`)
+)
+
+// ParseGroupName extracts Renovate's groupName from a grouped update title,
+// e.g. "Update all non-major dependencies" -> "all non-major dependencies" or
+// "chore(deps): update eslint monorepo to v9" -> "eslint monorepo".
+// Returns "" for titles that update a single dependency.
+func ParseGroupName(title string) string {
+	title = strings.TrimSpace(title)
+	if lockFileMaintenance.MatchString(title) {
+		return "lock file maintenance"
+	}
+
+	m := groupTitlePattern.FindStringSubmatch(title)
+	if m == nil {
+		return ""
+	}
+
+	subject := strings.TrimSpace(m[1])
+	if singleDependencySubject.MatchString(subject) {
+		return ""
+	}
+	return strings.ToLower(subject)
 }
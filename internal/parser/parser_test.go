@@ -24,6 +24,18 @@ func TestParseTitle(t *testing.T) {
 			wantPkg: "axios",
 			wantVer: "1.7.3",
 		},
+		{
+			name:    "Dependabot bump pattern ending a sentence",
+			title:   "bump lodash from 4.17.20 to 4.17.21.",
+			wantPkg: "lodash",
+			wantVer: "4.17.21",
+		},
+		{
+			name:    "Bump pattern from a version range",
+			title:   "Bump lodash from ^1.0.0 to 2.0.0",
+			wantPkg: "lodash",
+			wantVer: "2.0.0",
+		},
 		{
 			name:    "Dependabot update pattern",
 			title:   "Update typescript to 5.6.0",
@@ -77,6 +89,50 @@ func TestParseTitle(t *testing.T) {
 			wantVer: "5.89.0",
 		},
 
+		// Non-semver versions
+		{
+			name:    "GitHub Actions SHA pin",
+			title:   "Bump actions/checkout from 8e5e7e5 to 11bd719",
+			wantPkg: "actions/checkout",
+			wantVer: "11bd719",
+		},
+		{
+			name:    "Docker digest in backticks",
+			title:   "Bump golang from `1a2b3c4` to `5d6e7f8` in /docker",
+			wantPkg: "golang",
+			wantVer: "5d6e7f8",
+		},
+		{
+			name:    "Renovate digest update",
+			title:   "chore(deps): update docker/build-push-action digest to 4a13e50",
+			wantPkg: "docker/build-push-action",
+			wantVer: "4a13e50",
+		},
+		{
+			name:    "Renovate action major-only tag",
+			title:   "Update actions/checkout action to v4",
+			wantPkg: "actions/checkout",
+			wantVer: "4",
+		},
+		{
+			name:    "Dependabot major-only tag",
+			title:   "Bump actions/setup-go from 5 to 6",
+			wantPkg: "actions/setup-go",
+			wantVer: "6",
+		},
+		{
+			name:    "Docker tag with variant",
+			title:   "Bump node from 18-alpine to 20-alpine",
+			wantPkg: "node",
+			wantVer: "20-alpine",
+		},
+		{
+			name:    "Calver",
+			title:   "Bump certifi from 2024.8.30 to 2024.10.1",
+			wantPkg: "certifi",
+			wantVer: "2024.10.1",
+		},
+
 		// Unknown/parse failures
 		{
 			name:    "Unparseable title",
@@ -146,3 +202,149 @@ func TestGroupKey(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTitleCustomPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		patterns []string
+		want     PackageUpdate
+	}{
+		{
+			name:     "positional groups",
+			title:    "deps: upgrade foo to 1.2.3",
+			patterns: []string{`deps:\s+upgrade\s+([^\s]+)\s+to\s+(\d+\.\d+\.\d+)`},
+			want:     PackageUpdate{Package: "foo", Name: "foo", ToVersion: "1.2.3"},
+		},
+		{
+			name:     "named groups in any order",
+			title:    "[npm] 2.0.0 is now available for left-pad (was 1.0.0) in /web",
+			patterns: []string{`\[(?P<ecosystem>\w+)\]\s+(?P<to>\S+) is now available for (?P<package>\S+) \(was (?P<from>[^)]+)\) in (?P<directory>\S+)`},
+			want: PackageUpdate{
+				Package:     "left-pad",
+				Name:        "left-pad",
+				FromVersion: "1.0.0",
+				ToVersion:   "2.0.0",
+				Ecosystem:   "npm",
+				Directory:   "/web",
+			},
+		},
+		{
+			name:     "custom pattern wins over built-in",
+			title:    "Bump lodash from 4.17.20 to 4.17.21",
+			patterns: []string{`Bump (?P<package>\S+) from \S+ to (?P<to>\d+)`},
+			want:     PackageUpdate{Package: "lodash", Name: "lodash", ToVersion: "4"},
+		},
+		{
+			name:     "falls through to built-in when custom does not match",
+			title:    "Bump lodash from 4.17.20 to 4.17.21",
+			patterns: []string{`upgrade (?P<package>\S+) to (?P<to>\S+)`},
+			want:     PackageUpdate{Package: "lodash", Name: "lodash", FromVersion: "4.17.20", ToVersion: "4.17.21"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTitle(tt.title, tt.patterns)
+			if got != tt.want {
+				t.Errorf("ParseTitle(%q) = %+v, want %+v", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{"two positional groups", `bump (\S+) to (\S+)`, false},
+		{"named package and to", `bump (?P<package>\S+) to (?P<to>\S+)`, false},
+		{"named with optional fields", `(?P<package>\S+) from (?P<from>\S+) to (?P<to>\S+) in (?P<directory>\S+)`, false},
+		{"invalid regex", `bump (\S+ to (\S+)`, true},
+		{"one positional group", `bump (\S+)`, true},
+		{"three positional groups", `(\S+) from (\S+) to (\S+)`, true},
+		{"missing named to", `bump (?P<package>\S+) to \S+`, true},
+		{"unknown named group", `bump (?P<package>\S+) to (?P<to>\S+) (?P<extra>\S+)`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTitleDirectory(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		wantDir string
+		wantKey string
+	}{
+		{
+			name:    "Dependabot subdirectory",
+			title:   "Bump axios from 1.6.0 to 1.7.3 in /services/api",
+			wantDir: "/services/api",
+			wantKey: "axios@1.7.3",
+		},
+		{
+			name:    "trailing slash",
+			title:   "Bump axios from 1.6.0 to 1.7.3 in /web/",
+			wantDir: "/web",
+			wantKey: "axios@1.7.3",
+		},
+		{
+			name:    "repository root",
+			title:   "Bump axios from 1.6.0 to 1.7.3 in /",
+			wantDir: "",
+			wantKey: "axios@1.7.3",
+		},
+		{
+			name:    "no directory",
+			title:   "Bump axios from 1.6.0 to 1.7.3",
+			wantDir: "",
+			wantKey: "axios@1.7.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTitle(tt.title, nil)
+			if got.Directory != tt.wantDir {
+				t.Errorf("Directory = %q, want %q", got.Directory, tt.wantDir)
+			}
+			if got.GroupKey() != tt.wantKey {
+				t.Errorf("GroupKey() = %q, want %q", got.GroupKey(), tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestParseGroupName(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Update all non-major dependencies", "all non-major dependencies"},
+		{"chore(deps): update eslint monorepo to v9", "eslint monorepo"},
+		{"Update React monorepo to v18.3.1 (major)", "react monorepo"},
+		{"chore(deps): lock file maintenance", "lock file maintenance"},
+		{"Update dependency eslint to 8.57.0", ""},
+		{"Update actions/checkout action to v4", ""},
+		{"Update typescript to 5.6.0", ""},
+		{"Bump lodash from 4.17.20 to 4.17.21", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := ParseGroupName(tt.title); got != tt.want {
+				t.Errorf("ParseGroupName(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
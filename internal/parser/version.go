@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// VersionKind classifies the format of a version string
+type VersionKind int
+
+const (
+	VersionUnknown VersionKind = iota
+	VersionDigest              // commit SHA pins and image digests: 8e5e7e5, sha256:2b3c...
+	VersionMajor               // major-only tags: v4, 20-alpine
+	VersionSemver              // dotted versions: 1.2.3, v1.2, 1.2.3-rc.1
+	VersionCalver              // calendar versions: 2024.10.1, 2024-10-01
+)
+
+func (k VersionKind) String() string {
+	switch k {
+	case VersionDigest:
+		return "digest"
+	case VersionMajor:
+		return "major-only"
+	case VersionSemver:
+		return "semver"
+	case VersionCalver:
+		return "calver"
+	default:
+		return "unknown"
+	}
+}
+
+// Update types derived from comparing two versions
+const (
+	UpdateMajor   = "major"
+	UpdateMinor   = "minor"
+	UpdatePatch   = "patch"
+	UpdateDigest  = "digest"
+	UpdateUnknown = "unknown"
+)
+
+// digestLength is the number of hex characters kept for digests so that the
+// short SHAs used by Dependabot and Renovate group with full-length ones
+const digestLength = 7
+
+var (
+	digestPattern = regexp.MustCompile(`^(?:sha256:)?[0-9a-f]*[a-f][0-9a-f]*$`)
+	calverPattern = regexp.MustCompile(`^((?:19|20)\d{2})[.-](\d{1,2})(?:[.-](\d+))?(?:[.-](\d+))?([-+].*)?$`)
+	semverPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?(?:\.(\d+))?([-+].*)?$`)
+	majorPattern  = regexp.MustCompile(`^(\d+)([-+].*)?$`)
+)
+
+// Version is a parsed version string
+type Version struct {
+	Raw    string
+	Kind   VersionKind
+	Parts  []int  // numeric components for semver, calver and major-only versions
+	Suffix string // pre-release, build or variant suffix such as "-rc.1" or "-alpine"
+}
+
+// ParseVersion classifies a version string and extracts its numeric parts
+func ParseVersion(raw string) Version {
+	v := Version{Raw: raw}
+	s := NormalizeVersion(raw)
+
+	if m := calverPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = VersionCalver
+		v.Parts = atoiParts(m[1:5])
+		v.Suffix = m[5]
+		return v
+	}
+	if m := semverPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = VersionSemver
+		v.Parts = atoiParts(m[1:5])
+		v.Suffix = m[5]
+		return v
+	}
+	if m := majorPattern.FindStringSubmatch(s); m != nil {
+		v.Kind = VersionMajor
+		v.Parts = atoiParts(m[1:2])
+		v.Suffix = m[2]
+		return v
+	}
+	if len(s) >= digestLength && digestPattern.MatchString(s) {
+		v.Kind = VersionDigest
+		return v
+	}
+
+	return v
+}
+
+// NormalizeVersion strips decorations from a captured version so equivalent
+// versions produce the same group key: surrounding backticks, a leading "v"
+// before a number, and digests are lowercased and shortened.
+func NormalizeVersion(raw string) string {
+	s := strings.Trim(strings.TrimSpace(raw), "`")
+
+	if len(s) > 1 && (s[0] == 'v' || s[0] == 'V') && s[1] >= '0' && s[1] <= '9' {
+		s = s[1:]
+	}
+
+	lower := strings.ToLower(s)
+	if hex := strings.TrimPrefix(lower, "sha256:"); len(hex) >= digestLength && digestPattern.MatchString(lower) {
+		return hex[:digestLength]
+	}
+
+	return s
+}
+
+// Compare orders two versions. Semver, calver and major-only versions are
+// compared by their numeric parts, so "5" sorts above "4.2.0". With equal
+// parts a pre-release sorts before the release; variant suffixes such as
+// "-alpine" or "-jre" and build metadata ("+build.1") aren't pre-releases,
+// and only order the variants of a release among themselves. Digests and
+// unparseable versions sort before the others and by their raw string.
+func (v Version) Compare(other Version) int {
+	if !v.numeric() || !other.numeric() {
+		if v.Kind != other.Kind {
+			return cmp.Compare(v.Kind, other.Kind)
+		}
+		return strings.Compare(v.Raw, other.Raw)
+	}
+
+	for i := 0; i < max(len(v.Parts), len(other.Parts)); i++ {
+		if c := cmp.Compare(part(v.Parts, i), part(other.Parts, i)); c != 0 {
+			return c
+		}
+	}
+
+	vPre, otherPre := v.Prerelease(), other.Prerelease()
+	switch {
+	case vPre && !otherPre:
+		return -1
+	case otherPre && !vPre:
+		return 1
+	default:
+		return strings.Compare(v.Suffix, other.Suffix)
+	}
+}
+
+// Prerelease reports whether the suffix marks a pre-release, e.g. "-rc.1".
+// Variant suffixes and build metadata don't.
+func (v Version) Prerelease() bool {
+	if v.Suffix == "" || strings.HasPrefix(v.Suffix, "+") {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(v.Suffix), isSuffixSeparator)
+	if len(words) == 0 {
+		return false
+	}
+	// "alpine3.19" is still alpine
+	return !slices.Contains(variantSuffixes, strings.TrimRightFunc(words[0], unicode.IsDigit))
+}
+
+// variantSuffixes name the builds of a release published side by side, as
+// image tags and Maven versions do: 20-alpine, 3.12-slim, 33.0.0-jre
+var variantSuffixes = []string{
+	"alpine", "slim", "bookworm", "bullseye", "buster", "trixie", "jammy", "noble", "focal",
+	"windowsservercore", "nanoserver", "jre", "jdk", "android",
+}
+
+func isSuffixSeparator(r rune) bool {
+	return r == '-' || r == '.' || r == '_' || r == '+'
+}
+
+// numeric reports whether the version is compared by its numeric parts
+func (v Version) numeric() bool {
+	return v.Kind == VersionMajor || v.Kind == VersionSemver || v.Kind == VersionCalver
+}
+
+// CompareVersions parses and compares two version strings
+func CompareVersions(a, b string) int {
+	return ParseVersion(a).Compare(ParseVersion(b))
+}
+
+// UpdateType reports whether moving from one version to another is a major,
+// minor, patch or digest update. Calendar versions treat the year as the
+// major component. Returns "unknown" when the versions can't be compared.
+func UpdateType(from, to string) string {
+	f := ParseVersion(from)
+	t := ParseVersion(to)
+
+	if f.Kind == VersionDigest || t.Kind == VersionDigest {
+		return UpdateDigest
+	}
+	if f.Kind == VersionUnknown || t.Kind == VersionUnknown {
+		return UpdateUnknown
+	}
+
+	switch {
+	case part(f.Parts, 0) != part(t.Parts, 0):
+		return UpdateMajor
+	case f.Kind == VersionMajor || t.Kind == VersionMajor:
+		return UpdateUnknown
+	case part(f.Parts, 1) != part(t.Parts, 1):
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
+// UpdateType returns the kind of update between FromVersion and ToVersion
+func (u PackageUpdate) UpdateType() string {
+	if u.FromVersion == "" {
+		return UpdateUnknown
+	}
+	return UpdateType(u.FromVersion, u.ToVersion)
+}
+
+// CompareGroupKeys orders package@version keys by package name, then by version
+func CompareGroupKeys(a, b string) int {
+	aPkg, aVer := splitGroupKey(a)
+	bPkg, bVer := splitGroupKey(b)
+
+	if c := strings.Compare(aPkg, bPkg); c != 0 {
+		return c
+	}
+	if c := CompareVersions(aVer, bVer); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// splitGroupKey splits a package@version key on the last "@" so scoped
+// packages like @types/node keep their leading "@". A ":/directory" suffix
+// is left out of the version.
+func splitGroupKey(key string) (string, string) {
+	idx := strings.LastIndex(key, "@")
+	if idx <= 0 {
+		return key, ""
+	}
+	ver, _, _ := strings.Cut(key[idx+1:], ":")
+	return key[:idx], ver
+}
+
+func atoiParts(groups []string) []int {
+	var parts []int
+	for _, g := range groups {
+		if g == "" {
+			break
+		}
+		n, err := strconv.Atoi(g)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+func part(parts []int, i int) int {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return 0
+}
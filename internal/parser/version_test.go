@@ -0,0 +1,125 @@
+package parser
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		raw      string
+		wantKind VersionKind
+	}{
+		{"1.2.3", VersionSemver},
+		{"v1.2", VersionSemver},
+		{"1.2.3-rc.1", VersionSemver},
+		{"2024.10.1", VersionCalver},
+		{"2024-10-01", VersionCalver},
+		{"v4", VersionMajor},
+		{"20-alpine", VersionMajor},
+		{"8e5e7e5", VersionDigest},
+		{"sha256:2b3c4d5e6f7a8b9c", VersionDigest},
+		{"latest", VersionUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got := ParseVersion(tt.raw)
+			if got.Kind != tt.wantKind {
+				t.Errorf("ParseVersion(%q).Kind = %s, want %s", tt.raw, got.Kind, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"v1.7.3", "1.7.3"},
+		{"`1a2b3c4`", "1a2b3c4"},
+		{"11BD719C8C1E0F5E5A4C6B3B2A1F0E9D8C7B6A5F", "11bd719"},
+		{"sha256:2b3c4d5e6f7a8b9c", "2b3c4d5"},
+		{"version", "version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := NormalizeVersion(tt.raw); got != tt.want {
+				t.Errorf("NormalizeVersion(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"v2", "1", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"2024.10.1", "2024.9.30", 1},
+		{"1.2", "1.2.0", 0},
+		{"5", "4.2.0", 1},
+		{"v4", "4.0.1", -1},
+		{"2024.10.1", "5.0.0", 1},
+		{"4.2.0", "8e5e7e5", 1},
+		{"1.2.3-alpine", "1.2.3", 1},
+		{"1.2.3-alpine3.19", "1.2.3-rc.1", 1},
+		{"33.0.0-jre", "33.0.0-rc1-jre", 1},
+		{"1.2.3+build.5", "1.2.3-beta", 1},
+		{"20-alpine", "21", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"4.17.20", "4.17.21", UpdatePatch},
+		{"1.6.0", "1.7.3", UpdateMinor},
+		{"4.5.2", "5.6.0", UpdateMajor},
+		{"v3", "v4", UpdateMajor},
+		{"2023.12.1", "2024.1.0", UpdateMajor},
+		{"2024.9.1", "2024.10.1", UpdateMinor},
+		{"8e5e7e5", "11bd719", UpdateDigest},
+		{"latest", "1.0.0", UpdateUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if got := UpdateType(tt.from, tt.to); got != tt.want {
+				t.Errorf("UpdateType(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareGroupKeys(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"lodash@4.17.9", "lodash@4.17.21", -1},
+		{"@types/node@20.10.0", "@types/node@18.0.0", 1},
+		{"axios@1.7.3", "lodash@1.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := CompareGroupKeys(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareGroupKeys(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
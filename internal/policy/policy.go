@@ -0,0 +1,259 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// Decision is what a policy allows gh-dep to do with a PR
+type Decision string
+
+const (
+	Allow       Decision = "allow"        // approve and merge
+	Deny        Decision = "deny"         // never approve or merge
+	NeedsReview Decision = "needs-review" // a person must approve before merging
+)
+
+// Decisions lists the valid decisions
+var Decisions = []Decision{Allow, Deny, NeedsReview}
+
+// AtLeastAsStrict reports whether d restricts PRs at least as much as
+// other: deny is stricter than needs-review, which is stricter than allow.
+// An empty decision is allow.
+func (d Decision) AtLeastAsStrict(other Decision) bool {
+	return d.strictness() >= other.strictness()
+}
+
+func (d Decision) strictness() int {
+	switch d {
+	case Deny:
+		return 2
+	case NeedsReview:
+		return 1
+	}
+	return 0
+}
+
+// updateTypes lists the update types rules can match
+var updateTypes = []string{parser.UpdateMajor, parser.UpdateMinor, parser.UpdatePatch, parser.UpdateDigest, parser.UpdateUnknown}
+
+// Policy is an ordered list of rules. The first rule matching a PR decides;
+// PRs no rule matches get the default decision.
+type Policy struct {
+	Default Decision `yaml:"default"` // allow when empty
+	Rules   []Rule   `yaml:"rules"`
+}
+
+// Rule applies a decision to the PRs it matches
+type Rule struct {
+	Name          string   `yaml:"name"`
+	Match         Match    `yaml:"match"`
+	Decision      Decision `yaml:"decision"`       // allow when empty
+	Approvals     int      `yaml:"approvals"`      // approvals needed to merge with needs-review (default 1)
+	RequireChecks *bool    `yaml:"require-checks"` // overrides the merge setting for matching PRs
+
+	Source string `yaml:"-"` // config file the rule came from
+}
+
+// Match selects PRs. Every field that is set must match; an empty Match
+// matches every PR.
+type Match struct {
+	Packages    []string `yaml:"packages"`     // package name globs, e.g. react or @types/*
+	Ecosystems  []string `yaml:"ecosystems"`   // e.g. npm, github-actions
+	UpdateTypes []string `yaml:"update-types"` // major, minor, patch, digest, or unknown
+	Repos       []string `yaml:"repos"`        // OWNER/REPO globs
+	Authors     []string `yaml:"authors"`      // PR author logins
+	Title       string   `yaml:"title"`        // regex matched against the PR title
+
+	title *regexp.Regexp
+}
+
+// Result is the outcome of evaluating a policy for a PR
+type Result struct {
+	Decision      Decision
+	Rule          string // name of the matching rule, "" for the default
+	Approvals     int    // approvals needed to merge, 0 when none are
+	RequireChecks *bool  // CI requirement set by the rule, nil to keep the merge setting
+}
+
+// Validate checks the decisions, patterns and update types of every rule,
+// and compiles the title patterns Evaluate matches against. A policy must
+// be validated before it's evaluated.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if err := validateDecision(p.Default); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if err := r.validate(); err != nil {
+			name := r.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			if r.Source != "" {
+				return fmt.Errorf("rule %s (from %s): %w", name, r.Source, err)
+			}
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	if err := validateDecision(r.Decision); err != nil {
+		return err
+	}
+	if r.Approvals < 0 {
+		return fmt.Errorf("invalid approvals %d (must not be negative)", r.Approvals)
+	}
+
+	for _, glob := range append(slices.Clone(r.Match.Packages), r.Match.Repos...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", glob, err)
+		}
+	}
+	for _, t := range r.Match.UpdateTypes {
+		if !slices.Contains(updateTypes, t) {
+			return fmt.Errorf("invalid update type %q (expected one of %s)", t, strings.Join(updateTypes, ", "))
+		}
+	}
+	if r.Match.Title != "" {
+		re, err := regexp.Compile(r.Match.Title)
+		if err != nil {
+			return fmt.Errorf("invalid title regex %q: %w", r.Match.Title, err)
+		}
+		r.Match.title = re
+	}
+	return nil
+}
+
+func validateDecision(d Decision) error {
+	if d == "" || slices.Contains(Decisions, d) {
+		return nil
+	}
+	return fmt.Errorf("invalid decision %q (expected allow, deny, or needs-review)", d)
+}
+
+// Evaluate decides what may be done with a PR, given the update parsed
+// from its title. A nil policy allows everything. Reaching a rule whose
+// title pattern wasn't compiled by Validate denies the PR.
+func (p *Policy) Evaluate(pr types.PR, update parser.PackageUpdate) Result {
+	if p == nil {
+		return Result{Decision: Allow}
+	}
+
+	for _, r := range p.Rules {
+		if r.Match.Title != "" && r.Match.title == nil {
+			// Validate wasn't run, so the title can't be checked; fail closed
+			return Result{Decision: Deny, Rule: r.Name}
+		}
+		if r.Match.matches(pr, update) {
+			return r.result()
+		}
+	}
+
+	return Rule{Decision: p.Default}.result()
+}
+
+func (r Rule) result() Result {
+	res := Result{Decision: r.Decision, Rule: r.Name, RequireChecks: r.RequireChecks}
+	if res.Decision == "" {
+		res.Decision = Allow
+	}
+	if res.Decision == NeedsReview {
+		res.Approvals = max(r.Approvals, 1)
+	}
+	return res
+}
+
+func (m Match) matches(pr types.PR, update parser.PackageUpdate) bool {
+	if len(m.Packages) > 0 && !matchAny(m.Packages, strings.ToLower(update.Name), strings.ToLower(update.Package)) {
+		return false
+	}
+	if len(m.Ecosystems) > 0 && !slices.ContainsFunc(m.Ecosystems, func(e string) bool {
+		return parser.NormalizeEcosystem(e) == parser.NormalizeEcosystem(update.Ecosystem)
+	}) {
+		return false
+	}
+	if len(m.UpdateTypes) > 0 && !slices.Contains(m.UpdateTypes, update.UpdateType()) {
+		return false
+	}
+	if len(m.Repos) > 0 && !matchAny(m.Repos, strings.ToLower(pr.Repo)) {
+		return false
+	}
+	if len(m.Authors) > 0 && !slices.ContainsFunc(m.Authors, func(a string) bool {
+		return strings.EqualFold(a, pr.Author)
+	}) {
+		return false
+	}
+	if m.title != nil && !m.title.MatchString(pr.Title) {
+		return false
+	}
+	return true
+}
+
+// matchAny reports whether any glob matches any of the values
+func matchAny(globs []string, values ...string) bool {
+	for _, glob := range globs {
+		glob = strings.ToLower(glob)
+		for _, value := range values {
+			if ok, _ := path.Match(glob, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String describes the decision and the rule that made it, e.g.
+// `needs-review (rule "react-major")`
+func (r Result) String() string {
+	if r.Rule == "" {
+		return string(r.Decision) + " (default policy)"
+	}
+	return fmt.Sprintf("%s (rule %q)", r.Decision, r.Rule)
+}
+
+// CheckApprove returns an error explaining why the PR must not be approved
+// by gh-dep, or nil if it may be
+func (r Result) CheckApprove() error {
+	switch r.Decision {
+	case Deny:
+		return fmt.Errorf("denied by policy: %s", r)
+	case NeedsReview:
+		return fmt.Errorf("needs a human review: %s", r)
+	}
+	return nil
+}
+
+// CheckMerge returns an error explaining why the PR must not be merged, or
+// nil if it may be. approvals is only called when the rule requires a
+// number of approvals.
+func (r Result) CheckMerge(approvals func() (int, error)) error {
+	if r.Decision == Deny {
+		return fmt.Errorf("denied by policy: %s", r)
+	}
+	if r.Approvals == 0 {
+		return nil
+	}
+
+	n, err := approvals()
+	if err != nil {
+		return fmt.Errorf("failed to count approvals: %w", err)
+	}
+	if n < r.Approvals {
+		return fmt.Errorf("has %d of %d required approvals: %s", n, r.Approvals, r)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func examplePolicy(t *testing.T) *Policy {
+	t.Helper()
+	p := &Policy{
+		Default: NeedsReview,
+		Rules: []Rule{
+			{Name: "deny-list", Match: Match{Packages: []string{"event-stream", "colors"}}, Decision: Deny},
+			{Name: "react-major", Match: Match{Packages: []string{"react", "react-*"}, UpdateTypes: []string{"major"}}, Decision: NeedsReview, Approvals: 2},
+			{Name: "action-digests", Match: Match{Packages: []string{"actions/*"}, UpdateTypes: []string{"digest"}}, RequireChecks: boolPtr(false)},
+			{Name: "dev-patches", Match: Match{UpdateTypes: []string{"patch"}, Title: `\(deps-dev\)`}, Decision: Allow},
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	return p
+}
+
+func TestEvaluate(t *testing.T) {
+	p := examplePolicy(t)
+
+	tests := []struct {
+		title         string
+		wantDecision  Decision
+		wantRule      string
+		wantApprovals int
+	}{
+		{"Bump event-stream from 3.3.4 to 3.3.6", Deny, "deny-list", 0},
+		{"Bump react from 18.3.1 to 19.0.0", NeedsReview, "react-major", 2},
+		{"Bump react-dom from 18.3.1 to 19.0.0", NeedsReview, "react-major", 2},
+		{"Bump react from 18.3.0 to 18.3.1", NeedsReview, "", 1},
+		{"Bump actions/checkout from 8e5e7e5 to 11bd719", Allow, "action-digests", 0},
+		{"chore(deps-dev): bump eslint from 8.57.0 to 8.57.1", Allow, "dev-patches", 0},
+		{"chore(deps): bump express from 4.19.0 to 4.19.2", NeedsReview, "", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			pr := types.PR{Title: tt.title, Repo: "myorg/app"}
+			got := p.Evaluate(pr, parser.ParseTitle(tt.title, nil))
+			if got.Decision != tt.wantDecision || got.Rule != tt.wantRule || got.Approvals != tt.wantApprovals {
+				t.Errorf("Evaluate() = %+v, want %s by %q with %d approvals", got, tt.wantDecision, tt.wantRule, tt.wantApprovals)
+			}
+		})
+	}
+}
+
+func TestEvaluateNilPolicyAllows(t *testing.T) {
+	var p *Policy
+	if got := p.Evaluate(types.PR{}, parser.PackageUpdate{}); got.Decision != Allow {
+		t.Errorf("Evaluate() = %+v, want allow", got)
+	}
+}
+
+func TestEvaluateUnvalidatedTitleDenies(t *testing.T) {
+	p := &Policy{Rules: []Rule{{Name: "bad-title", Match: Match{Title: "("}, Decision: Allow}}}
+	if got := p.Evaluate(types.PR{Title: "Bump lodash"}, parser.PackageUpdate{}); got.Decision != Deny || got.Rule != "bad-title" {
+		t.Errorf("Evaluate() = %+v, want deny by bad-title", got)
+	}
+}
+
+func TestCheckMerge(t *testing.T) {
+	count := func(n int) func() (int, error) {
+		return func() (int, error) { return n, nil }
+	}
+	unused := func() (int, error) {
+		t.Error("approvals counted for a rule that doesn't need them")
+		return 0, nil
+	}
+
+	if err := (Result{Decision: Allow}).CheckMerge(unused); err != nil {
+		t.Errorf("allow: %v", err)
+	}
+	if err := (Result{Decision: Deny, Rule: "deny-list"}).CheckMerge(unused); err == nil || !strings.Contains(err.Error(), `rule "deny-list"`) {
+		t.Errorf("deny: %v", err)
+	}
+
+	review := Result{Decision: NeedsReview, Rule: "react-major", Approvals: 2}
+	if err := review.CheckMerge(count(1)); err == nil || !strings.Contains(err.Error(), "has 1 of 2 required approvals") {
+		t.Errorf("one approval: %v", err)
+	}
+	if err := review.CheckMerge(count(2)); err != nil {
+		t.Errorf("two approvals: %v", err)
+	}
+	if err := review.CheckMerge(func() (int, error) { return 0, errors.New("boom") }); err == nil {
+		t.Error("expected the count error to block the merge")
+	}
+
+	if err := review.CheckApprove(); err == nil || !strings.Contains(err.Error(), "needs a human review") {
+		t.Errorf("CheckApprove() = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   string
+	}{
+		{name: "decision", policy: Policy{Rules: []Rule{{Name: "r", Decision: "block"}}}, want: `rule r: invalid decision "block"`},
+		{name: "update type", policy: Policy{Rules: []Rule{{Match: Match{UpdateTypes: []string{"huge"}}}}}, want: `rule #1: invalid update type "huge"`},
+		{name: "glob", policy: Policy{Rules: []Rule{{Name: "r", Match: Match{Packages: []string{"[react"}}}}}, want: "invalid pattern"},
+		{name: "title", policy: Policy{Rules: []Rule{{Name: "r", Match: Match{Title: "("}}}}, want: "invalid title regex"},
+		{name: "default", policy: Policy{Default: "maybe"}, want: "default: invalid decision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
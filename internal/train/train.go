@@ -0,0 +1,139 @@
+// Package train merges the PRs of a repo one at a time. Before each merge
+// the PR is brought up to date with its base branch, since the merges ahead
+// of it often leave it behind or conflicting on the lockfile, and its CI is
+// awaited again.
+package train
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// Options configure the steps of a train
+type Options struct {
+	Bots          *bots.Registry // rebase commands of the PR authors
+	RequireChecks bool           // wait for CI to pass before merging
+	Merge         executor.Func  // merges a PR that's ready
+}
+
+// Step takes a PR one step closer to being merged, for use with
+// executor.Options.Train: it returns a pending result while the PR is being
+// rebased or its CI runs, and merges it with opts.Merge once it's ready. A
+// rebase is only requested when the PR's turn comes, since rebasing the PRs
+// behind it would be undone by its merge anyway.
+func Step(pr types.PR, opts Options) executor.Result {
+	details, err := github.GetPR(pr.Repo, pr.Number)
+	if err != nil {
+		return executor.Pending(pr, "merge", fmt.Errorf("failed to fetch PR: %w", err))
+	}
+	if details.Merged {
+		return executor.Skip(pr, "merge", errors.New("already merged"))
+	}
+	if details.State == "closed" {
+		return executor.Skip(pr, "merge", errors.New("closed"))
+	}
+
+	switch {
+	case details.Mergeable == nil || details.MergeableState == "unknown":
+		// GitHub computes mergeability in the background after a merge
+		return executor.Pending(pr, "merge", errors.New("waiting for GitHub to check mergeability"))
+	case NeedsRebase(details.MergeableState):
+		requested, err := Rebase(pr, details.HeadSHA, opts.Bots)
+		if err != nil {
+			return executor.Fail(pr, "rebase", err)
+		}
+		r := executor.Pending(pr, "merge", fmt.Errorf("waiting for %s", requested))
+		r.Retry = waitForRebase(details.HeadSHA, requested, opts)
+		return r
+	}
+
+	if opts.RequireChecks {
+		status, err := github.GetCIStatus(pr.Repo, details.HeadSHA)
+		if err != nil {
+			return executor.Pending(pr, "merge", fmt.Errorf("failed to check CI status: %w", err))
+		}
+		if status.State == "pending" {
+			return executor.Pending(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
+		}
+		if !status.AllPassed {
+			return executor.Fail(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
+		}
+	}
+
+	return opts.Merge(pr)
+}
+
+// waitForRebase returns the retry of a PR whose rebase was requested: it
+// stays pending until the head moves, then carries on with Step
+func waitForRebase(oldSHA, requested string, opts Options) executor.Func {
+	return func(pr types.PR) executor.Result {
+		sha, err := github.GetPRHead(pr.Repo, pr.Number)
+		if err == nil && sha == oldSHA {
+			return executor.Pending(pr, "merge", fmt.Errorf("waiting for %s", requested))
+		}
+		return Step(pr, opts)
+	}
+}
+
+// NeedsRebase reports whether a PR must be brought up to date before it
+// can be merged: it's behind a base branch that requires it, or conflicts
+func NeedsRebase(mergeableState string) bool {
+	return mergeableState == "behind" || mergeableState == "dirty"
+}
+
+type rebaseKind int
+
+const (
+	rebaseUpdateBranch rebaseKind = iota // merge the base branch into the PR
+	rebaseComment                        // comment the bot's rebase command
+	rebaseLabel                          // add the bot's rebase label
+)
+
+// rebaseMethod picks how to rebase a PR: through its bot when the bot
+// takes a rebase command or label, else by updating the branch
+func rebaseMethod(pr types.PR, registry *bots.Registry) (rebaseKind, string) {
+	if registry == nil {
+		return rebaseUpdateBranch, ""
+	}
+	b, ok := registry.ForPR(pr.Author, pr.HeadRef)
+	if !ok {
+		return rebaseUpdateBranch, ""
+	}
+
+	switch {
+	case b.Commands.Rebase != "":
+		return rebaseComment, b.Commands.Rebase
+	case b.Commands.RebaseLabel != "":
+		return rebaseLabel, b.Commands.RebaseLabel
+	}
+	return rebaseUpdateBranch, ""
+}
+
+// Rebase brings a PR up to date with its base branch: its bot is asked to
+// rebase it when it can be, otherwise the base branch is merged into the
+// PR's head. Returns what was requested, e.g. `a rebase ("@dependabot rebase")`.
+func Rebase(pr types.PR, headSHA string, registry *bots.Registry) (string, error) {
+	kind, value := rebaseMethod(pr, registry)
+	switch kind {
+	case rebaseComment:
+		if err := github.CommentOnPR(pr.Repo, pr.Number, value); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("a rebase (%q)", value), nil
+	case rebaseLabel:
+		if err := github.AddLabel(pr.Repo, pr.Number, value); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("a rebase (label %q)", value), nil
+	}
+
+	if err := github.UpdateBranch(pr.Repo, pr.Number, headSHA); err != nil {
+		return "", err
+	}
+	return "the branch update", nil
+}
@@ -0,0 +1,61 @@
+package train
+
+import (
+	"testing"
+
+	"github.com/jackchuka/gh-dep/internal/bots"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+func TestRebaseMethod(t *testing.T) {
+	registry, err := bots.NewRegistry([]bots.Bot{
+		{Name: "acme-deps", Logins: []string{"acme-deps[bot]"}},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	tests := []struct {
+		author    string
+		wantKind  rebaseKind
+		wantValue string
+	}{
+		{"dependabot[bot]", rebaseComment, "@dependabot rebase"},
+		{"renovate[bot]", rebaseLabel, "rebase"},
+		{"acme-deps[bot]", rebaseUpdateBranch, ""},
+		{"octocat", rebaseUpdateBranch, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.author, func(t *testing.T) {
+			kind, value := rebaseMethod(types.PR{Author: tt.author}, registry)
+			if kind != tt.wantKind || value != tt.wantValue {
+				t.Errorf("rebaseMethod() = %v, %q, want %v, %q", kind, value, tt.wantKind, tt.wantValue)
+			}
+		})
+	}
+
+	// Renovate under a service account is recognized by its branch
+	pr := types.PR{Author: "acme-ci", HeadRef: "renovate/lodash-4.x"}
+	if kind, value := rebaseMethod(pr, registry); kind != rebaseLabel || value != "rebase" {
+		t.Errorf("rebaseMethod() by branch = %v, %q, want the rebase label", kind, value)
+	}
+
+	if kind, _ := rebaseMethod(types.PR{Author: "dependabot[bot]"}, nil); kind != rebaseUpdateBranch {
+		t.Errorf("rebaseMethod() without a registry = %v, want a branch update", kind)
+	}
+}
+
+func TestNeedsRebase(t *testing.T) {
+	for state, want := range map[string]bool{
+		"behind":   true,
+		"dirty":    true,
+		"clean":    false,
+		"unstable": false,
+		"blocked":  false,
+	} {
+		if got := NeedsRebase(state); got != want {
+			t.Errorf("NeedsRebase(%q) = %v, want %v", state, got, want)
+		}
+	}
+}
@@ -2,15 +2,23 @@ package tui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
 	"github.com/jackchuka/gh-dep/internal/github"
+	"github.com/jackchuka/gh-dep/internal/policy"
+	"github.com/jackchuka/gh-dep/internal/train"
 	"github.com/jackchuka/gh-dep/internal/types"
 )
 
-// executeSelected returns a command that executes actions on selected PRs
+// executeSelected starts executing the action on the selected PRs. Results
+// arrive as executor.Result messages, merges waiting for CI as
+// executionPendingMsg, and executionCompleteMsg ends the run.
 func (m *Model) executeSelected() tea.Cmd {
-	// Get list of selected PRs
 	var selectedPRs []types.PR
 	for i, pr := range m.filteredPRs {
 		if m.selected[i] {
@@ -18,99 +26,283 @@ func (m *Model) executeSelected() tea.Cmd {
 		}
 	}
 
-	// Return a batch of commands - one for each PR
-	var cmds []tea.Cmd
-	for _, pr := range selectedPRs {
-		cmds = append(cmds, m.executePRCmd(pr))
+	events := make(chan tea.Msg)
+	m.events = events
+	m.executionTotal = len(selectedPRs)
+	m.waiting = make(map[string]executor.Result)
+
+	opts := executor.Options{
+		Concurrency: m.concurrency,
+		OnEvent: func(e executor.Event) {
+			switch e.Kind {
+			case executor.EventFinished:
+				events <- e.Result
+			case executor.EventPending:
+				events <- executionPendingMsg{e.Result}
+			}
+		},
+	}
+	if m.mergeWhenReady || m.train {
+		opts.Wait = m.waitTimeout
 	}
+	// Approving alone has nothing to wait for
+	opts.Train = m.train && m.mode != ModeApprove
 
-	// Run all PR commands concurrently, but only mark completion after they all finish.
-	return tea.Sequence(
-		tea.Batch(cmds...),
-		func() tea.Msg { return executionCompleteMsg{} },
-	)
+	go func() {
+		execute := m.executePR
+		if m.atomic && m.mode != ModeApprove {
+			blockers := m.atomicBlockers(selectedPRs)
+			execute = func(pr types.PR) executor.Result {
+				if err, ok := blockers[fmt.Sprintf("%s#%d", pr.Repo, pr.Number)]; ok {
+					return executor.Skip(pr, "merge", err)
+				}
+				return m.executePR(pr)
+			}
+		}
+
+		summary := executor.Run(selectedPRs, execute, opts)
+		events <- executionCompleteMsg{summary: summary}
+	}()
+
+	return m.waitForExecution()
 }
 
-// executePRCmd creates a command to execute action on a single PR
-func (m *Model) executePRCmd(pr types.PR) tea.Cmd {
+// waitForExecution returns a command delivering the next execution message
+func (m *Model) waitForExecution() tea.Cmd {
+	events := m.events
 	return func() tea.Msg {
-		switch m.mode {
-		case ModeApprove:
-			return m.approvePR(pr)
-		case ModeMerge:
-			return m.mergePR(pr)
-		case ModeApproveAndMerge:
-			// First approve
-			approveResult := m.approvePR(pr)
-			if !approveResult.Success {
+		return <-events
+	}
+}
+
+// executePR executes the current action on a single PR
+func (m *Model) executePR(pr types.PR) executor.Result {
+	rule := m.policy.Evaluate(pr, m.groupOptions.ParseUpdate(pr))
+	switch m.mode {
+	case ModeApprove:
+		return m.approvePR(pr, rule)
+	case ModeMerge:
+		return m.mergePR(pr, rule)
+	case ModeApproveAndMerge:
+		// First approve, unless a person has to; merging then waits
+		// for their approvals
+		if rule.Decision != policy.NeedsReview {
+			approveResult := m.approvePR(pr, rule)
+			if approveResult.Status != executor.StatusDone {
 				return approveResult
 			}
-			// Then merge
-			return m.mergePR(pr)
 		}
-		return ExecutionResult{
-			PR:      pr,
-			Action:  "unknown",
-			Success: false,
-			Error:   fmt.Errorf("unknown execution mode"),
+		// Then merge; a merge waiting for CI is retried without approving again
+		result := m.mergePR(pr, rule)
+		if result.Status == executor.StatusPending && result.Retry == nil {
+			result.Retry = func(pr types.PR) executor.Result {
+				return m.mergePR(pr, rule)
+			}
 		}
+		return result
 	}
+	return executor.Fail(pr, "unknown", fmt.Errorf("unknown execution mode"))
 }
 
-func (m *Model) approvePR(pr types.PR) ExecutionResult {
-	err := github.ApprovePR(pr.Repo, pr.Number)
-	return ExecutionResult{
-		PR:      pr,
-		Action:  "approve",
-		Success: err == nil,
-		Error:   err,
+func (m *Model) approvePR(pr types.PR, rule policy.Result) executor.Result {
+	if err := m.config.CheckAllowed(pr.Repo, config.ActionApprove); err != nil {
+		return executor.Skip(pr, "approve", err)
+	}
+	if err := rule.CheckApprove(); err != nil {
+		return executor.Skip(pr, "approve", err)
+	}
+
+	if err := github.ApprovePR(pr.Repo, pr.Number); err != nil {
+		return executor.Fail(pr, "approve", err)
 	}
+	return executor.Done(pr, "approve", "")
 }
 
-func (m *Model) mergePR(pr types.PR) ExecutionResult {
+func (m *Model) mergePR(pr types.PR, rule policy.Result) executor.Result {
+	if err := m.config.CheckAllowed(pr.Repo, config.ActionMerge); err != nil {
+		return executor.Skip(pr, "merge", err)
+	}
+
+	err := rule.CheckMerge(func() (int, error) {
+		return github.CountApprovals(pr.Repo, pr.Number)
+	})
+	if err != nil {
+		return executor.Skip(pr, "merge", err)
+	}
+
+	method, requireChecks, deleteBranch := m.mergeSettings(pr.Repo)
+	if rule.RequireChecks != nil {
+		requireChecks = *rule.RequireChecks
+	}
+
+	action := "merge (api, " + method + ")"
+	merge := func(pr types.PR) executor.Result {
+		if err := github.MergeViaPR(pr.Repo, pr.Number, method); err != nil {
+			return executor.Fail(pr, action, err)
+		}
+
+		if deleteBranch {
+			// The merge went through, so a failed branch deletion isn't
+			// reported as a failure
+			_ = github.DeleteHeadBranch(pr.Repo, pr.Number)
+		}
+		return executor.Done(pr, action, "")
+	}
+
+	if m.train {
+		return train.Step(pr, train.Options{Bots: m.groupOptions.Bots, RequireChecks: requireChecks, Merge: merge})
+	}
+
 	// Check CI status if required
-	if m.requireChecks {
+	if requireChecks {
+		// While waiting, the bot may push a new head, so always use the current one
 		headSHA := pr.HeadSHA
-		if headSHA == "" {
+		if headSHA == "" || m.mergeWhenReady {
 			sha, err := github.GetPRHead(pr.Repo, pr.Number)
 			if err != nil {
-				return ExecutionResult{
-					PR:      pr,
-					Action:  "merge (skipped)",
-					Success: false,
-					Error:   fmt.Errorf("failed to fetch PR head: %w", err),
-				}
+				return executor.Skip(pr, "merge", fmt.Errorf("failed to fetch PR head: %w", err))
 			}
 			headSHA = sha
 		}
 
 		status, err := github.GetCIStatus(pr.Repo, headSHA)
 		if err != nil {
-			return ExecutionResult{
-				PR:      pr,
-				Action:  "merge (skipped)",
-				Success: false,
-				Error:   fmt.Errorf("failed to check CI status: %w", err),
-			}
+			return executor.Skip(pr, "merge", fmt.Errorf("failed to check CI status: %w", err))
 		}
 
+		if status.State == "pending" {
+			// Retried while merge when ready is on
+			return executor.Pending(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
+		}
 		if !status.AllPassed {
-			return ExecutionResult{
-				PR:      pr,
-				Action:  "merge (skipped)",
-				Success: false,
-				Error:   fmt.Errorf("CI checks not passing (state: %s)", status.State),
-			}
+			return executor.Skip(pr, "merge", fmt.Errorf("CI checks not passing (state: %s)", status.State))
 		}
 	}
 
-	err := github.MergeViaPR(pr.Repo, pr.Number, m.mergeMethod)
-	action := "merge (api)"
+	return merge(pr)
+}
 
-	return ExecutionResult{
-		PR:      pr,
-		Action:  action,
-		Success: err == nil,
-		Error:   err,
+// mergeSettings returns the merge method, CI requirement and branch
+// deletion for a repo. The repo's config overrides win over the settings
+// toggled in the TUI.
+func (m *Model) mergeSettings(repo string) (string, bool, bool) {
+	method, requireChecks, deleteBranch := m.mergeMethod, m.requireChecks, false
+	if m.config == nil {
+		return method, requireChecks, deleteBranch
 	}
+
+	o := m.config.OverrideFor(repo).Merge
+	if o.Method != "" {
+		method = o.Method
+	}
+	if o.RequireChecks != nil {
+		requireChecks = *o.RequireChecks
+	}
+	if d := m.config.MergeFor(repo).DeleteBranch; d != nil {
+		deleteBranch = *d
+	}
+	return method, requireChecks, deleteBranch
+}
+
+// atomicBlockers checks every PR before merging and returns, by repo#number,
+// why the PRs of groups that can't be merged in full are skipped
+func (m *Model) atomicBlockers(prs []types.PR) map[string]error {
+	summary := executor.Run(prs, m.checkPR, executor.Options{Concurrency: m.concurrency})
+
+	// The first PR blocking each group
+	blockedBy := make(map[string]executor.Result)
+	for _, r := range summary.Results {
+		group := github.GroupKey(r.PR, m.groupOptions)
+		if _, ok := blockedBy[group]; !ok && r.Status != executor.StatusDone {
+			blockedBy[group] = r
+		}
+	}
+
+	blockers := make(map[string]error)
+	for _, r := range summary.Results {
+		first, ok := blockedBy[github.GroupKey(r.PR, m.groupOptions)]
+		switch {
+		case !ok:
+			continue
+		case r.Status != executor.StatusDone:
+			blockers[resultKey(r)] = fmt.Errorf("blocks its group: %w", r.Err)
+		default:
+			blockers[resultKey(r)] = fmt.Errorf("group blocked by %s", resultKey(first))
+		}
+	}
+	return blockers
+}
+
+// checkPR reports whether a PR could be merged now as done, or why not
+func (m *Model) checkPR(pr types.PR) executor.Result {
+	if err := m.config.CheckAllowed(pr.Repo, config.ActionMerge); err != nil {
+		return executor.Skip(pr, "check", err)
+	}
+
+	// In Approve & Merge mode the approvals are given first, so reviews
+	// are only judged when a person has to give them
+	rule := m.policy.Evaluate(pr, m.groupOptions.ParseUpdate(pr))
+	reviews := m.mode == ModeMerge || rule.Decision == policy.NeedsReview
+	if !reviews {
+		rule.Approvals = 0
+	}
+	err := rule.CheckMerge(func() (int, error) {
+		return github.CountApprovals(pr.Repo, pr.Number)
+	})
+	if err != nil {
+		return executor.Skip(pr, "check", err)
+	}
+
+	_, requireChecks, _ := m.mergeSettings(pr.Repo)
+	if rule.RequireChecks != nil {
+		requireChecks = *rule.RequireChecks
+	}
+
+	details, err := github.GetPR(pr.Repo, pr.Number)
+	if err != nil {
+		return executor.Skip(pr, "check", fmt.Errorf("failed to fetch PR: %w", err))
+	}
+	if m.train && details.State == "open" && !details.Draft && train.NeedsRebase(details.MergeableState) {
+		// The train rebases it when its turn comes, and checks CI then
+		return executor.Done(pr, "check", "")
+	}
+
+	var status *github.CheckStatus
+	if requireChecks && !details.Merged {
+		status, err = github.GetCIStatus(pr.Repo, details.HeadSHA)
+		if err != nil {
+			return executor.Skip(pr, "check", fmt.Errorf("failed to check CI status: %w", err))
+		}
+	}
+	if err := github.MergeBlocker(details, status, reviews); err != nil {
+		return executor.Skip(pr, "check", err)
+	}
+	return executor.Done(pr, "check", "")
+}
+
+// recordResult writes the outcome of an action into the group cache so
+// gh dep groups and later runs see it. Skipped merges leave the PR pending.
+func recordResult(result executor.Result) {
+	var status, reason string
+	switch {
+	case result.Status == executor.StatusSkipped:
+		return
+	case result.Status == executor.StatusFailed:
+		status = types.StatusFailed
+		reason = fmt.Sprintf("%s: %v", result.Action, result.Err)
+	case strings.HasPrefix(result.Action, "merge"):
+		status = types.StatusMerged
+	case result.Action == "approve":
+		status = types.StatusApproved
+	default:
+		return
+	}
+
+	// The TUI has nowhere to report a cache write error; the action itself
+	// already succeeded or failed
+	_ = cache.RecordState(result.PR.Repo, result.PR.Number, types.PRState{
+		Status:    status,
+		Reason:    reason,
+		UpdatedAt: time.Now(),
+	})
 }
@@ -2,15 +2,21 @@ package tui
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jackchuka/gh-dep/internal/config"
+	"github.com/jackchuka/gh-dep/internal/executor"
 	"github.com/jackchuka/gh-dep/internal/github"
-	"github.com/jackchuka/gh-dep/internal/parser"
+	"github.com/jackchuka/gh-dep/internal/policy"
 	"github.com/jackchuka/gh-dep/internal/types"
+	"github.com/jackchuka/gh-dep/internal/ui"
 )
 
 type ExecutionMode int
@@ -34,6 +40,10 @@ func (m ExecutionMode) String() string {
 	}
 }
 
+// defaultWaitTimeout is how long merge when ready waits for pending CI
+// unless SetMergeWhenReady sets a timeout
+const defaultWaitTimeout = 30 * time.Minute
+
 type ViewState int
 
 const (
@@ -43,13 +53,6 @@ const (
 	ViewHelp
 )
 
-type ExecutionResult struct {
-	PR      types.PR
-	Action  string
-	Success bool
-	Error   error
-}
-
 type Model struct {
 	prs             []types.PR
 	filteredPRs     []types.PR
@@ -60,9 +63,18 @@ type Model struct {
 	searchInput     textinput.Model
 	searching       bool
 	searchQuery     string
-	groupFilter     string   // current group filter key (e.g., "lodash@4.17.21")
-	customPatterns  []string // custom parsing patterns from config
-	executionResult []ExecutionResult
+	groupFilter     string              // current group filter key (e.g., "lodash@4.17.21")
+	groupOptions    github.GroupOptions // custom parsing patterns and grouping settings
+	executionResult []executor.Result
+	executionTotal  int              // PRs being executed
+	summary         executor.Summary // outcome of the last execution
+	events          chan tea.Msg     // execution results, then executionCompleteMsg
+	concurrency     int              // repos executed at once
+	mergeWhenReady  bool             // wait for pending CI and merge once it passes
+	waitTimeout     time.Duration    // how long merge when ready and the train wait
+	train           bool             // merge one PR per repo at a time, rebasing each first
+	atomic          bool             // merge a group's PRs only if all of them can be merged
+	waiting         map[string]executor.Result
 	executing       bool
 	refetching      bool
 	mergeMethod     string
@@ -70,6 +82,10 @@ type Model struct {
 	width           int
 	height          int
 	searchParams    github.SearchParams // For refetching PRs
+	offline         bool                // showing a cached snapshot; actions are queued
+	snapshotAt      time.Time           // when the cached snapshot was fetched
+	config          *config.Config      // per-repo overrides and policy
+	policy          *policy.Policy      // rules deciding what may be approved and merged
 }
 
 type keyMap struct {
@@ -81,6 +97,9 @@ type keyMap struct {
 	ToggleMode    key.Binding
 	ToggleMethod  key.Binding
 	ToggleChecks  key.Binding
+	ToggleWait    key.Binding
+	ToggleTrain   key.Binding
+	ToggleAtomic  key.Binding
 	Execute       key.Binding
 	Search        key.Binding
 	GroupFilter   key.Binding
@@ -125,6 +144,18 @@ var keys = keyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "toggle CI checks"),
 	),
+	ToggleWait: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "toggle merge when ready"),
+	),
+	ToggleTrain: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "toggle merge train"),
+	),
+	ToggleAtomic: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "toggle atomic group merges"),
+	),
 	Execute: key.NewBinding(
 		key.WithKeys("x"),
 		key.WithHelp("x", "execute"),
@@ -135,7 +166,7 @@ var keys = keyMap{
 	),
 	GroupFilter: key.NewBinding(
 		key.WithKeys("g"),
-		key.WithHelp("g", "filter same package"),
+		key.WithHelp("g", "filter same group"),
 	),
 	OpenBrowser: key.NewBinding(
 		key.WithKeys("o"),
@@ -209,28 +240,38 @@ var (
 
 	ciUnknownStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
+
+	dirStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	offlineStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("226")).
+			Bold(true)
 )
 
-func NewModel(prs []types.PR, mergeMethod string, requireChecks bool, mode ExecutionMode, searchParams github.SearchParams, customPatterns []string) *Model {
+func NewModel(prs []types.PR, mergeMethod string, requireChecks bool, mode ExecutionMode, searchParams github.SearchParams, groupOptions github.GroupOptions) *Model {
 	ti := textinput.New()
 	ti.Placeholder = "Search PRs..."
 	ti.CharLimit = 100
 
+	github.AnnotatePRs(prs, groupOptions)
+
 	m := &Model{
-		prs:            prs,
-		filteredPRs:    prs,
-		selected:       make(map[int]bool),
-		cursor:         0,
-		mode:           mode,
-		view:           ViewList,
-		searchInput:    ti,
-		searching:      false,
-		searchQuery:    "",
-		groupFilter:    "",
-		customPatterns: customPatterns,
-		mergeMethod:    mergeMethod,
-		requireChecks:  requireChecks,
-		searchParams:   searchParams,
+		prs:           prs,
+		filteredPRs:   prs,
+		selected:      make(map[int]bool),
+		cursor:        0,
+		mode:          mode,
+		view:          ViewList,
+		searchInput:   ti,
+		searching:     false,
+		searchQuery:   "",
+		groupFilter:   "",
+		groupOptions:  groupOptions,
+		mergeMethod:   mergeMethod,
+		requireChecks: requireChecks,
+		searchParams:  searchParams,
+		waitTimeout:   defaultWaitTimeout,
 	}
 
 	// Apply initial filtering based on requireChecks
@@ -241,6 +282,42 @@ func NewModel(prs []types.PR, mergeMethod string, requireChecks bool, mode Execu
 	return m
 }
 
+// SetMergeWhenReady makes merges wait up to timeout for pending CI and
+// merge once it passes; the w key toggles it
+func (m *Model) SetMergeWhenReady(enabled bool, timeout time.Duration) {
+	m.mergeWhenReady = enabled
+	m.waitTimeout = timeout
+	m.filterPRs()
+}
+
+// SetTrain makes merges run as a train: one PR per repo at a time, each
+// rebased when its turn comes and merged once its CI passes again; the t
+// key toggles it
+func (m *Model) SetTrain(enabled bool) {
+	m.train = enabled
+	m.filterPRs()
+}
+
+// SetAtomic makes merges all-or-nothing per group: the selected PRs are
+// checked first, and a group is merged only if all of them can be; the A
+// key toggles it
+func (m *Model) SetAtomic(enabled bool) {
+	m.atomic = enabled
+}
+
+// SetConcurrency sets how many repos actions are executed in at once; PRs
+// of the same repo always run one at a time. Zero uses the executor default.
+func (m *Model) SetConcurrency(n int) {
+	m.concurrency = n
+}
+
+// SetConfig sets the configuration whose per-repo overrides and policy
+// apply when executing actions
+func (m *Model) SetConfig(cfg *config.Config) {
+	m.config = cfg
+	m.policy = &cfg.Policy
+}
+
 func (m *Model) Init() tea.Cmd {
 	return nil
 }
@@ -265,12 +342,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 			if msg.String() == "enter" || msg.String() == "esc" {
-				// Refetch PRs from GitHub to update the list
 				m.clearSearch()
 				m.view = ViewList
 				m.executionResult = nil
 				m.selected = make(map[int]bool)
 				m.cursor = 0
+				// Queuing changed nothing on GitHub; keep the snapshot
+				if m.offline {
+					return m, nil
+				}
+				// Refetch PRs from GitHub to update the list
 				m.refetching = true
 				return m, m.refetchPRs()
 			}
@@ -353,6 +434,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.filterPRs()
 			m.cursor = 0
 
+		case key.Matches(msg, keys.ToggleWait):
+			m.mergeWhenReady = !m.mergeWhenReady
+			m.filterPRs()
+			m.cursor = 0
+
+		case key.Matches(msg, keys.ToggleTrain):
+			m.train = !m.train
+			m.filterPRs()
+			m.cursor = 0
+
+		case key.Matches(msg, keys.ToggleAtomic):
+			m.atomic = !m.atomic
+
 		case key.Matches(msg, keys.Search):
 			m.searching = true
 			m.searchInput.Focus()
@@ -361,8 +455,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, keys.GroupFilter):
 			if len(m.filteredPRs) > 0 && m.cursor < len(m.filteredPRs) {
 				currentPR := m.filteredPRs[m.cursor]
-				update := parser.ParseTitle(currentPR.Title, m.customPatterns)
-				groupKey := update.GroupKey()
+				groupKey := github.GroupKey(currentPR, m.groupOptions)
 
 				// Toggle: if already filtering by this group, clear it
 				if m.groupFilter == groupKey {
@@ -387,6 +480,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.refetchPRs()
 
 		case key.Matches(msg, keys.Execute):
+			if m.hasSelection() && m.offline {
+				m.queueSelected()
+				m.view = ViewComplete
+				return m, nil
+			}
 			if m.hasSelection() {
 				m.executing = true
 				m.view = ViewExecuting
@@ -394,18 +492,27 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-	case ExecutionResult:
+	case executor.Result:
+		delete(m.waiting, resultKey(msg))
 		m.executionResult = append(m.executionResult, msg)
-		return m, nil
+		recordResult(msg)
+		return m, m.waitForExecution()
+
+	case executionPendingMsg:
+		m.waiting[resultKey(msg.Result)] = msg.Result
+		return m, m.waitForExecution()
 
 	case executionCompleteMsg:
 		m.executing = false
+		m.summary = msg.summary
 		m.view = ViewComplete
 		return m, nil
 
 	case refetchCompleteMsg:
 		// Update the PR list with the refetched data
 		m.refetching = false
+		m.offline = false
+		github.AnnotatePRs(msg.prs, m.groupOptions)
 		m.prs = msg.prs
 		m.filterPRs()
 		return m, nil
@@ -447,6 +554,17 @@ func (m *Model) renderList() string {
 		return s.String()
 	}
 
+	if m.offline {
+		// Entries cached before fetch times were recorded have none
+		fetched := "fetched at unknown time"
+		if !m.snapshotAt.IsZero() {
+			fetched = "from " + ui.FormatAge(time.Since(m.snapshotAt)) + " ago"
+		}
+		s.WriteString(offlineStyle.Render("Offline: cached snapshot " + fetched))
+		s.WriteString(helpStyle.Render(" • x queues actions for 'gh dep queue run' • r tries to refresh"))
+		s.WriteString("\n\n")
+	}
+
 	// Mode and settings indicator
 	s.WriteString(headerStyle.Render("Action: "))
 	s.WriteString(modeStyle.Render(m.mode.String()))
@@ -462,6 +580,24 @@ func (m *Model) renderList() string {
 		s.WriteString(modeStyle.Render("required"))
 	}
 
+	if m.mergeWhenReady {
+		s.WriteString("  ")
+		s.WriteString(headerStyle.Render("Merge: "))
+		s.WriteString(modeStyle.Render("when ready"))
+	}
+
+	if m.train {
+		s.WriteString("  ")
+		s.WriteString(headerStyle.Render("Train: "))
+		s.WriteString(modeStyle.Render("on"))
+	}
+
+	if m.atomic {
+		s.WriteString("  ")
+		s.WriteString(headerStyle.Render("Groups: "))
+		s.WriteString(modeStyle.Render("atomic"))
+	}
+
 	s.WriteString("\n\n")
 
 	// Search bar
@@ -476,7 +612,7 @@ func (m *Model) renderList() string {
 
 	// Group filter indicator
 	if m.groupFilter != "" {
-		s.WriteString(helpStyle.Render(fmt.Sprintf("Group filter: %s (press g to clear)", m.groupFilter)))
+		s.WriteString(helpStyle.Render(fmt.Sprintf("Group filter (%s): %s (press g to clear)", m.groupStrategy(), m.groupFilter)))
 		s.WriteString("\n\n")
 	}
 
@@ -504,11 +640,16 @@ func (m *Model) renderList() string {
 
 		ciStatus := formatCIStatus(pr.CIStatus)
 
+		repo := pr.Repo
+		if pr.Directory != "" {
+			repo += dirStyle.Render(" " + pr.Directory)
+		}
+
 		line := fmt.Sprintf("%s %s %s %s #%d - %s",
 			cursor,
 			checkbox,
 			ciStatus,
-			pr.Repo,
+			repo,
 			pr.Number,
 			pr.Title,
 		)
@@ -525,7 +666,7 @@ func (m *Model) renderList() string {
 	s.WriteString("\n")
 	s.WriteString(helpStyle.Render("↑/↓: navigate • space: select • a: select all • d: deselect all • r: refresh"))
 	s.WriteString("\n")
-	s.WriteString(helpStyle.Render("m/M/c: toggle settings • /: search • g: group • o: open • x: execute • ?: help • q: quit"))
+	s.WriteString(helpStyle.Render("m/M/c/w/t/A: toggle settings • /: search • g: group • o: open • x: execute • ?: help • q: quit"))
 
 	return s.String()
 }
@@ -533,28 +674,22 @@ func (m *Model) renderList() string {
 func (m *Model) renderExecuting() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render("Executing..."))
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Executing... (%d/%d)", len(m.executionResult), m.executionTotal)))
 	s.WriteString("\n\n")
 
 	for _, result := range m.executionResult {
-		status := successStyle.Render("✓")
-		if !result.Success {
-			status = errorStyle.Render("✗")
-		}
-
-		msg := fmt.Sprintf("%s %s %s #%d",
-			status,
-			result.Action,
-			result.PR.Repo,
-			result.PR.Number,
-		)
-
-		if !result.Success {
-			msg += errorStyle.Render(fmt.Sprintf(" - %v", result.Error))
-		}
+		s.WriteString(formatResult(result))
+		s.WriteString("\n")
+	}
 
-		s.WriteString(msg)
+	if len(m.waiting) > 0 {
 		s.WriteString("\n")
+		for _, key := range slices.Sorted(maps.Keys(m.waiting)) {
+			result := m.waiting[key]
+			fmt.Fprintf(&s, "%s %s %s #%d", helpStyle.Render("…"), result.Action, result.PR.Repo, result.PR.Number)
+			s.WriteString(helpStyle.Render(fmt.Sprintf(" - waiting: %v", result.Err)))
+			s.WriteString("\n")
+		}
 	}
 
 	if !m.executing {
@@ -568,44 +703,55 @@ func (m *Model) renderExecuting() string {
 func (m *Model) renderComplete() string {
 	var s strings.Builder
 
-	s.WriteString(titleStyle.Render("Execution Complete"))
+	if m.offline {
+		s.WriteString(titleStyle.Render("Actions Queued"))
+	} else {
+		s.WriteString(titleStyle.Render("Execution Complete"))
+	}
 	s.WriteString("\n\n")
 
-	successCount := 0
 	failCount := 0
-
 	for _, result := range m.executionResult {
-		status := successStyle.Render("✓")
-		if !result.Success {
-			status = errorStyle.Render("✗")
+		if result.Status == executor.StatusFailed {
 			failCount++
-		} else {
-			successCount++
 		}
-
-		msg := fmt.Sprintf("%s %s %s #%d",
-			status,
-			result.Action,
-			result.PR.Repo,
-			result.PR.Number,
-		)
-
-		if !result.Success {
-			msg += errorStyle.Render(fmt.Sprintf(" - %v", result.Error))
-		}
-
-		s.WriteString(msg)
+		s.WriteString(formatResult(result))
 		s.WriteString("\n")
 	}
 
 	s.WriteString("\n")
-	s.WriteString(headerStyle.Render(fmt.Sprintf("Summary: %d succeeded, %d failed", successCount, failCount)))
+	if m.offline {
+		s.WriteString(headerStyle.Render(fmt.Sprintf("Summary: %d queued, %d failed", len(m.executionResult)-failCount, failCount)))
+		s.WriteString("\n")
+		s.WriteString(helpStyle.Render("Run 'gh dep queue run' when back online"))
+	} else {
+		s.WriteString(headerStyle.Render("Summary: " + m.summary.String()))
+	}
 	s.WriteString("\n\n")
 	s.WriteString(helpStyle.Render("Press enter to return to list • q to quit"))
 
 	return s.String()
 }
 
+// formatResult renders one execution result line
+func formatResult(result executor.Result) string {
+	status := successStyle.Render("✓")
+	action := result.Action
+	switch result.Status {
+	case executor.StatusSkipped:
+		status = helpStyle.Render("-")
+		action += " (skipped)"
+	case executor.StatusFailed:
+		status = errorStyle.Render("✗")
+	}
+
+	msg := fmt.Sprintf("%s %s %s #%d", status, action, result.PR.Repo, result.PR.Number)
+	if result.Err != nil {
+		msg += errorStyle.Render(fmt.Sprintf(" - %v", result.Err))
+	}
+	return msg
+}
+
 func (m *Model) renderHelp() string {
 	var s strings.Builder
 
@@ -624,12 +770,15 @@ func (m *Model) renderHelp() string {
 		{"m", "Toggle action mode (Approve → Merge → Approve & Merge)"},
 		{"M", "Toggle merge method (squash → merge → rebase)"},
 		{"c", "Toggle CI checks requirement"},
+		{"w", "Toggle merge when ready: wait for pending CI and merge once it passes"},
+		{"t", "Toggle merge train: merge one PR per repo at a time, rebasing each first"},
+		{"A", "Toggle atomic group merges: merge a group's PRs only if all of them can be merged"},
 		{"/", "Enter search mode"},
-		{"g", "Filter by same package@version (toggle)"},
+		{"g", "Filter by same group, following --group-by and --by-directory (toggle)"},
 		{"esc", "Cancel search / clear filters"},
 		{"o", "Open current PR in browser"},
 		{"r", "Refresh PR list from GitHub"},
-		{"x", "Execute selected actions"},
+		{"x", "Execute selected actions (queue them when offline)"},
 		{"?", "Show/hide this help screen"},
 		{"q", "Quit the application"},
 	}
@@ -686,8 +835,7 @@ func (m *Model) filterPRs() {
 	for _, pr := range m.prs {
 		// Filter by group (package@version) if set
 		if m.groupFilter != "" {
-			update := parser.ParseTitle(pr.Title, m.customPatterns)
-			if update.GroupKey() != m.groupFilter {
+			if github.GroupKey(pr, m.groupOptions) != m.groupFilter {
 				continue
 			}
 		}
@@ -702,8 +850,10 @@ func (m *Model) filterPRs() {
 			}
 		}
 
-		// Filter by CI status if requireChecks is enabled
-		if m.requireChecks && pr.CIStatus != "success" {
+		// Filter by CI status if requireChecks is enabled; merge when
+		// ready and the train also keep PRs whose CI is still running
+		waits := m.mergeWhenReady || m.train
+		if m.requireChecks && pr.CIStatus != "success" && (!waits || pr.CIStatus != "pending") {
 			continue
 		}
 
@@ -717,6 +867,14 @@ func (m *Model) filterPRs() {
 	m.selected = newSelected
 }
 
+// groupStrategy returns the name of the active grouping strategy
+func (m *Model) groupStrategy() string {
+	if m.groupOptions.Strategy == "" {
+		return string(github.GroupByPackageVersion)
+	}
+	return string(m.groupOptions.Strategy)
+}
+
 func (m *Model) clearSearch() {
 	m.searching = false
 	m.searchQuery = ""
@@ -755,7 +913,19 @@ func (m *Model) refetchPRs() tea.Cmd {
 	}
 }
 
-type executionCompleteMsg struct{}
+// executionPendingMsg reports an action waiting to be retried
+type executionPendingMsg struct {
+	executor.Result
+}
+
+// resultKey identifies the PR of a result
+func resultKey(r executor.Result) string {
+	return fmt.Sprintf("%s#%d", r.PR.Repo, r.PR.Number)
+}
+
+type executionCompleteMsg struct {
+	summary executor.Summary
+}
 
 type refetchCompleteMsg struct {
 	prs []types.PR
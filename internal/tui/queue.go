@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/jackchuka/gh-dep/internal/cache"
+	"github.com/jackchuka/gh-dep/internal/executor"
+	"github.com/jackchuka/gh-dep/internal/types"
+)
+
+// Queued action names, as understood by gh dep queue run
+const (
+	QueueApprove         = "approve"
+	QueueMerge           = "merge"
+	QueueApproveAndMerge = "approve-and-merge"
+)
+
+// queueAction returns the queued action name for the mode
+func (m ExecutionMode) queueAction() string {
+	switch m {
+	case ModeMerge:
+		return QueueMerge
+	case ModeApproveAndMerge:
+		return QueueApproveAndMerge
+	default:
+		return QueueApprove
+	}
+}
+
+// SetSnapshot marks the PRs as a cached snapshot fetched at fetchedAt.
+// Until the list is refreshed from GitHub, executing queues the actions
+// instead of running them.
+func (m *Model) SetSnapshot(fetchedAt time.Time) {
+	m.offline = true
+	m.snapshotAt = fetchedAt
+}
+
+// queueSelected queues the current action for the selected PRs and
+// reports each one as a result
+func (m *Model) queueSelected() {
+	now := time.Now()
+	var actions []types.QueuedAction
+	var prs []types.PR
+	for i, pr := range m.filteredPRs {
+		if !m.selected[i] {
+			continue
+		}
+		prs = append(prs, pr)
+		method, requireChecks, _ := m.mergeSettings(pr.Repo)
+		actions = append(actions, types.QueuedAction{
+			Repo:          pr.Repo,
+			Number:        pr.Number,
+			Title:         pr.Title,
+			URL:           pr.URL,
+			Author:        pr.Author,
+			Action:        m.mode.queueAction(),
+			MergeMethod:   method,
+			RequireChecks: requireChecks,
+			HeadSHA:       pr.HeadSHA,
+			QueuedAt:      now,
+		})
+	}
+
+	err := cache.Enqueue(actions)
+	action := "queue " + m.mode.queueAction()
+	for _, pr := range prs {
+		result := executor.Done(pr, action, "")
+		if err != nil {
+			result = executor.Fail(pr, action, err)
+		}
+		m.executionResult = append(m.executionResult, result)
+	}
+}
@@ -1,14 +1,46 @@
 package types
 
+import "time"
+
 // PR represents a pull request
 type PR struct {
-	Number   int    `json:"number"`
-	Title    string `json:"title"`
-	Author   string `json:"author"`
-	Repo     string `json:"repo"` // OWNER/REPO format
-	URL      string `json:"url"`
-	HeadSHA  string `json:"-"`         // For CI status checks
-	CIStatus string `json:"ci_status"` // CI status: success, pending, failure, or empty
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	Author    string   `json:"author"`
+	Repo      string   `json:"repo"` // OWNER/REPO format
+	URL       string   `json:"url"`
+	HeadSHA   string   `json:"head_sha,omitempty"`  // For CI status checks
+	HeadRef   string   `json:"head_ref,omitempty"`  // Head branch name
+	CIStatus  string   `json:"ci_status"`           // CI status: success, pending, failure, or empty
+	Directory string   `json:"directory,omitempty"` // Directory parsed from the title, e.g. /services/api
+	State     *PRState `json:"state,omitempty"`     // Outcome of the last action on the PR, nil while pending
+}
+
+// PR statuses recorded after an action
+const (
+	StatusApproved = "approved"
+	StatusMerged   = "merged"
+	StatusClosed   = "closed"
+	StatusFailed   = "failed"
+)
+
+// PRState records the outcome of the last approve or merge of a PR
+type PRState struct {
+	Status    string    `json:"status"`             // approved, merged, closed, or failed
+	Reason    string    `json:"reason,omitempty"`   // why the action failed
+	Approved  bool      `json:"approved,omitempty"` // approved at some point, kept across later failures
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether the PR needs no further action: it has been merged
+// or closed
+func (s *PRState) Done() bool {
+	return s != nil && (s.Status == StatusMerged || s.Status == StatusClosed)
+}
+
+// IsApproved reports whether the PR has been approved or is already done
+func (s *PRState) IsApproved() bool {
+	return s != nil && (s.Approved || s.Status == StatusApproved || s.Done())
 }
 
 // Group represents a collection of PRs for the same package@version
@@ -17,7 +49,49 @@ type Group struct {
 	PRs []PR
 }
 
-// Cache represents the cached groups from list --group
+// Cache represents the cached groups from list --group, keyed by search scope
 type Cache struct {
-	Groups map[string][]PR `json:"groups"` // key: package@version, value: list of PRs
+	Version int                    `json:"version"`        // schema version of the cache file
+	Last    string                 `json:"last,omitempty"` // scope of the most recent list --group
+	Entries map[string]*CacheEntry `json:"entries"`        // key: scope, e.g. owner=myorg or repo=owner/app
+}
+
+// CacheEntry holds the groups fetched for one search scope
+type CacheEntry struct {
+	Scope       string          `json:"scope"`
+	FetchedAt   time.Time       `json:"fetched_at"`
+	Params      SearchRecord    `json:"params"`                 // search that produced the groups
+	Groups      map[string][]PR `json:"groups"`                 // key: group key (package@version by default), value: list of PRs
+	GroupBy     string          `json:"group_by,omitempty"`     // grouping strategy used to build Groups
+	ByDirectory bool            `json:"by_directory,omitempty"` // whether groups were split by directory
+}
+
+// SearchRecord records the search parameters behind a cache entry
+type SearchRecord struct {
+	Owner           string   `json:"owner,omitempty"`
+	Repos           []string `json:"repos,omitempty"`
+	Label           string   `json:"label,omitempty"`
+	Authors         []string `json:"authors,omitempty"`
+	Limit           int      `json:"limit,omitempty"`
+	ReviewRequested string   `json:"review_requested,omitempty"`
+	Archived        bool     `json:"archived,omitempty"`
+	HeadPrefixes    []string `json:"head_prefixes,omitempty"`
+	DiscoverLabels  []string `json:"discover_labels,omitempty"`
+	ExcludeLabels   []string `json:"exclude_labels,omitempty"`
+	ExcludeRepos    []string `json:"exclude_repos,omitempty"`
+	Topics          []string `json:"topics,omitempty"`
+}
+
+// QueuedAction is an action chosen while offline, run later by gh dep queue run
+type QueuedAction struct {
+	Repo          string    `json:"repo"`
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	Author        string    `json:"author,omitempty"` // for policy rules and title patterns by author
+	Action        string    `json:"action"`           // approve, merge, or approve-and-merge
+	MergeMethod   string    `json:"merge_method,omitempty"`
+	RequireChecks bool      `json:"require_checks,omitempty"`
+	HeadSHA       string    `json:"head_sha,omitempty"` // head when queued; the action is dropped if it moved
+	QueuedAt      time.Time `json:"queued_at"`
 }
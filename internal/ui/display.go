@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/tableprinter"
 	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/jackchuka/gh-dep/internal/parser"
 	"github.com/jackchuka/gh-dep/internal/types"
 )
 
@@ -70,16 +73,30 @@ func (u *UI) DisplayGroups(groups map[string][]types.PR) error {
 	isTTY := term.IsTerminal(os.Stdout)
 	termWidth, _, _ := term.FromEnv().Size()
 
-	// Sort groups alphabetically for consistent output
+	// Sort groups by package, then version, for consistent output
 	sortedKeys := make([]string, 0, len(groups))
 	for k := range groups {
 		sortedKeys = append(sortedKeys, k)
 	}
-	sort.Strings(sortedKeys)
+	slices.SortFunc(sortedKeys, parser.CompareGroupKeys)
+
+	// Only show the directory column when some update isn't at the repo root
+	showDir := hasDirectories(groups)
+
+	// Only show progress once an action has been recorded for some PR
+	showState := hasStates(groups)
 
 	// Create single table for all groups
 	table := tableprinter.New(os.Stdout, isTTY, termWidth)
-	table.AddHeader([]string{"GROUP", "REPO", "PR", "URL"})
+	header := []string{"GROUP", "REPO"}
+	if showDir {
+		header = append(header, "DIR")
+	}
+	header = append(header, "PR")
+	if showState {
+		header = append(header, "STATE")
+	}
+	table.AddHeader(append(header, "URL"))
 
 	for _, key := range sortedKeys {
 		groupPRs := groups[key]
@@ -94,7 +111,9 @@ func (u *UI) DisplayGroups(groups map[string][]types.PR) error {
 
 		for i, pr := range groupPRs {
 			// Group name only on first row of each group
-			if i == 0 {
+			if i == 0 && showState {
+				table.AddField(fmt.Sprintf("%s (%d/%d done)", key, countDone(groupPRs), len(groupPRs)))
+			} else if i == 0 {
 				table.AddField(key)
 			} else {
 				table.AddField("")
@@ -104,7 +123,18 @@ func (u *UI) DisplayGroups(groups map[string][]types.PR) error {
 			repoShort := repoParts[len(repoParts)-1]
 			table.AddField(repoShort)
 
+			if showDir {
+				dir := pr.Directory
+				if dir == "" {
+					dir = "/"
+				}
+				table.AddField(dir)
+			}
+
 			table.AddField("#" + strconv.Itoa(pr.Number))
+			if showState {
+				table.AddField(FormatState(pr.State))
+			}
 			table.AddField(pr.URL)
 			table.EndRow()
 		}
@@ -113,6 +143,63 @@ func (u *UI) DisplayGroups(groups map[string][]types.PR) error {
 	return table.Render()
 }
 
+// DisplayScopes prints the cached scopes, marking the most recent one
+func (u *UI) DisplayScopes(entries []*types.CacheEntry, last string) error {
+	if u.json {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	isTTY := term.IsTerminal(os.Stdout)
+	termWidth, _, _ := term.FromEnv().Size()
+
+	table := tableprinter.New(os.Stdout, isTTY, termWidth)
+	table.AddHeader([]string{"SCOPE", "FETCHED", "AGE", "GROUPS", "PRS"})
+
+	for _, entry := range entries {
+		scope := entry.Scope
+		if scope == last {
+			scope += " (last)"
+		}
+		prCount := 0
+		for _, prs := range entry.Groups {
+			prCount += len(prs)
+		}
+
+		table.AddField(scope)
+		if entry.FetchedAt.IsZero() {
+			table.AddField("-")
+			table.AddField("-")
+		} else {
+			table.AddField(entry.FetchedAt.Local().Format("2006-01-02 15:04"))
+			table.AddField(FormatAge(time.Since(entry.FetchedAt)))
+		}
+		table.AddField(strconv.Itoa(len(entry.Groups)))
+		table.AddField(strconv.Itoa(prCount))
+		table.EndRow()
+	}
+
+	return table.Render()
+}
+
+// FormatAge renders a duration coarsely, e.g. "45s", "12m", "3h", "2d"
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 // PrintAction prints a standardized action message for a PR
 // Examples:
 //   - approve #123
@@ -171,6 +258,51 @@ func isMultiRepoGroups(groups map[string][]types.PR) bool {
 	return false
 }
 
+func hasDirectories(groups map[string][]types.PR) bool {
+	for _, prs := range groups {
+		for _, pr := range prs {
+			if pr.Directory != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasStates(groups map[string][]types.PR) bool {
+	for _, prs := range groups {
+		for _, pr := range prs {
+			if pr.State != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func countDone(prs []types.PR) int {
+	done := 0
+	for _, pr := range prs {
+		if pr.State.Done() {
+			done++
+		}
+	}
+	return done
+}
+
+// FormatState renders a PR's recorded state, e.g. "merged" or
+// "failed: merge: not mergeable"
+func FormatState(state *types.PRState) string {
+	switch {
+	case state == nil:
+		return "pending"
+	case state.Reason != "":
+		return state.Status + ": " + state.Reason
+	default:
+		return state.Status
+	}
+}
+
 func (u *UI) displayListJSON(prs []types.PR) error {
 	data, err := json.MarshalIndent(prs, "", "  ")
 	if err != nil {